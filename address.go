@@ -0,0 +1,86 @@
+package geoip2
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+)
+
+// AddressParseError is returned when an IP address passed as a string or a
+// net.IP cannot be parsed.
+type AddressParseError struct {
+	// Err is the underlying error, if any.
+	Err error
+	// Address is the address that could not be parsed.
+	Address string
+}
+
+func (e AddressParseError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("geoip2: invalid IP address %q", e.Address)
+	}
+	// The errors from netip already contain the address.
+	return fmt.Sprintf("geoip2: invalid IP address: %v", e.Err)
+}
+
+func (e AddressParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseAddr parses s as an IP address for use in lookups. Any IPv6 zone,
+// e.g., "%eth0", is removed, as it is not relevant to lookups, and IPv4-mapped
+// IPv6 addresses, e.g., "::ffff:81.2.69.142", are converted to IPv4
+// addresses. If s is not a valid IP address, an AddressParseError is
+// returned.
+func ParseAddr(s string) (netip.Addr, error) {
+	ip, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, AddressParseError{Address: s, Err: err}
+	}
+	return normalizeAddr(ip), nil
+}
+
+// AddrFromIP converts ip to a netip.Addr for use in lookups. As with
+// ParseAddr, IPv4-mapped IPv6 addresses, such as those returned by
+// net.ParseIP for IPv4 addresses, are converted to IPv4 addresses. If ip is
+// not a valid IP address, an AddressParseError is returned.
+func AddrFromIP(ip net.IP) (netip.Addr, error) {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return netip.Addr{}, AddressParseError{Address: ip.String()}
+	}
+	return normalizeAddr(addr), nil
+}
+
+func normalizeAddr(ip netip.Addr) netip.Addr {
+	return ip.WithZone("").Unmap()
+}
+
+// LookupString parses s with ParseAddr and looks it up using lookup, which is
+// typically a lookup method of a Reader, e.g.:
+//
+//	record, err := geoip2.LookupString(db.City, "81.2.69.142")
+//
+// If s is not a valid IP address, an AddressParseError is returned.
+func LookupString[T any](lookup func(netip.Addr) (*T, error), s string) (*T, error) {
+	ip, err := ParseAddr(s)
+	if err != nil {
+		return nil, err
+	}
+	return lookup(ip)
+}
+
+// LookupIP converts ip with AddrFromIP and looks it up using lookup, which is
+// typically a lookup method of a Reader, e.g.:
+//
+//	record, err := geoip2.LookupIP(db.City, net.ParseIP("81.2.69.142"))
+//
+// This eases migrating from version 1 of this package. If ip is not a valid
+// IP address, an AddressParseError is returned.
+func LookupIP[T any](lookup func(netip.Addr) (*T, error), ip net.IP) (*T, error) {
+	addr, err := AddrFromIP(ip)
+	if err != nil {
+		return nil, err
+	}
+	return lookup(addr)
+}
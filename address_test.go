@@ -0,0 +1,83 @@
+package geoip2
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAddr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected netip.Addr
+	}{
+		{"81.2.69.142", netip.MustParseAddr("81.2.69.142")},
+		{"::ffff:81.2.69.142", netip.MustParseAddr("81.2.69.142")},
+		{"2001:218::1", netip.MustParseAddr("2001:218::1")},
+		{"fe80::1%eth0", netip.MustParseAddr("fe80::1")},
+	}
+	for _, test := range tests {
+		ip, err := ParseAddr(test.input)
+		require.NoError(t, err, test.input)
+		assert.Equal(t, test.expected, ip, test.input)
+	}
+
+	for _, input := range []string{"", "not an ip", "81.2.69.256", "81.2.69.0/24"} {
+		_, err := ParseAddr(input)
+		var parseErr AddressParseError
+		require.ErrorAs(t, err, &parseErr, input)
+		assert.Equal(t, input, parseErr.Address)
+		assert.Contains(t, err.Error(), "geoip2: invalid IP address")
+	}
+}
+
+func TestAddrFromIP(t *testing.T) {
+	ip, err := AddrFromIP(net.ParseIP("81.2.69.142"))
+	require.NoError(t, err)
+	assert.Equal(t, netip.MustParseAddr("81.2.69.142"), ip)
+
+	ip, err = AddrFromIP(net.IPv4(81, 2, 69, 142).To4())
+	require.NoError(t, err)
+	assert.Equal(t, netip.MustParseAddr("81.2.69.142"), ip)
+
+	ip, err = AddrFromIP(net.ParseIP("2001:218::1"))
+	require.NoError(t, err)
+	assert.Equal(t, netip.MustParseAddr("2001:218::1"), ip)
+
+	for _, input := range []net.IP{nil, {1, 2, 3}} {
+		_, err = AddrFromIP(input)
+		require.ErrorAs(t, err, &AddressParseError{})
+	}
+}
+
+func TestLookupString(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	expected, err := reader.City(netip.MustParseAddr("81.2.69.160"))
+	require.NoError(t, err)
+
+	for _, input := range []string{"81.2.69.160", "::ffff:81.2.69.160"} {
+		record, err := LookupString(reader.City, input)
+		require.NoError(t, err)
+		assert.Equal(t, expected, record, input)
+	}
+
+	record, err := LookupIP(reader.City, net.ParseIP("81.2.69.160"))
+	require.NoError(t, err)
+	assert.Equal(t, expected, record)
+
+	_, err = LookupString(reader.City, "invalid")
+	require.ErrorAs(t, err, &AddressParseError{})
+	require.NotErrorAs(t, err, &InvalidMethodError{})
+
+	_, err = LookupString(reader.ASN, "81.2.69.160")
+	require.ErrorAs(t, err, &InvalidMethodError{})
+
+	_, err = LookupIP(reader.City, nil)
+	require.ErrorAs(t, err, &AddressParseError{})
+}
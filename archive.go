@@ -0,0 +1,196 @@
+package geoip2
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+	tarMagic  = []byte("ustar")
+)
+
+// tarMagicOffset is the offset of the magic field in a tar header.
+const tarMagicOffset = 257
+
+// maxArchiveDatabaseSize is the largest database, and the largest zip archive
+// read from an io.Reader, that is loaded into memory from an archive. As
+// archives are untrusted input, this bounds the memory used by one that
+// decompresses to far more data than any MaxMind database contains.
+var maxArchiveDatabaseSize int64 = 4 << 30
+
+// OpenArchive takes a string path to a .tar.gz, .tar, or .zip archive, such
+// as those provided by MaxMind for download, and returns a Reader struct or
+// an error. See OpenArchiveReader for details. Unlike with
+// OpenArchiveReader, a .zip archive is not loaded into memory as a whole.
+func OpenArchive(file string, options ...ReaderOption) (*Reader, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck // error is not relevant for a read-only file
+
+	header := make([]byte, len(zipMagic))
+	n, err := f.ReadAt(header, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if !bytes.HasPrefix(header[:n], zipMagic) {
+		return OpenArchiveReader(f, options...)
+	}
+
+	// The zip central directory is at the end of the archive, which the file
+	// provides random access to.
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(f, stat.Size())
+	if err != nil {
+		return nil, err
+	}
+	db, err := readZipDatabase(zr)
+	if err != nil {
+		return nil, err
+	}
+	return OpenBytes(db, options...)
+}
+
+// OpenArchiveReader takes an io.Reader providing a .tar.gz, .tar, or .zip
+// archive and returns a Reader struct or an error. The archive format is
+// detected from its contents. The archive must contain exactly one file with
+// the .mmdb extension, which is loaded into memory and opened as with
+// OpenBytes. Other files in the archive, such as the license, are ignored.
+// As the contents of a .zip archive are listed at its end, such an archive
+// is loaded into memory as a whole first.
+func OpenArchiveReader(r io.Reader, options ...ReaderOption) (*Reader, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(tarMagicOffset + len(tarMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	var db []byte
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close() //nolint:errcheck // all errors are reported by Read
+		db, err = readTarDatabase(gz)
+		if err != nil {
+			return nil, err
+		}
+	case bytes.HasPrefix(header, zipMagic):
+		archive, err := readLimited(br)
+		if err != nil {
+			return nil, err
+		}
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, err
+		}
+		db, err = readZipDatabase(zr)
+		if err != nil {
+			return nil, err
+		}
+	case len(header) > tarMagicOffset &&
+		bytes.HasPrefix(header[tarMagicOffset:], tarMagic):
+		db, err = readTarDatabase(br)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("geoip2: unsupported archive format")
+	}
+	return OpenBytes(db, options...)
+}
+
+func readTarDatabase(r io.Reader) ([]byte, error) {
+	tr := tar.NewReader(r)
+	var db []byte
+	var name string
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg || !isDatabaseFile(header.Name) {
+			continue
+		}
+		if db != nil {
+			return nil, multipleDatabasesError(name, header.Name)
+		}
+		name = header.Name
+		db, err = readLimited(tr)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if db == nil {
+		return nil, errNoDatabaseInArchive
+	}
+	return db, nil
+}
+
+func readZipDatabase(zr *zip.Reader) ([]byte, error) {
+	var dbFile *zip.File
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() || !isDatabaseFile(f.Name) {
+			continue
+		}
+		if dbFile != nil {
+			return nil, multipleDatabasesError(dbFile.Name, f.Name)
+		}
+		dbFile = f
+	}
+	if dbFile == nil {
+		return nil, errNoDatabaseInArchive
+	}
+
+	rc, err := dbFile.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close() //nolint:errcheck // all errors are reported by Read
+	return readLimited(rc)
+}
+
+// readLimited reads all of r, returning an error if it provides more than
+// maxArchiveDatabaseSize bytes.
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxArchiveDatabaseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxArchiveDatabaseSize {
+		return nil, fmt.Errorf(
+			"geoip2: archive contents exceed the maximum size of %d bytes",
+			maxArchiveDatabaseSize,
+		)
+	}
+	return data, nil
+}
+
+var errNoDatabaseInArchive = errors.New("geoip2: no .mmdb file found in archive")
+
+func multipleDatabasesError(first, second string) error {
+	return fmt.Errorf("geoip2: archive contains more than one .mmdb file: %s and %s", first, second)
+}
+
+func isDatabaseFile(name string) bool {
+	return path.Ext(name) == ".mmdb"
+}
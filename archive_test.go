@@ -0,0 +1,178 @@
+package geoip2
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type archiveEntry struct {
+	name string
+	file string
+}
+
+func TestOpenArchive(t *testing.T) {
+	entries := []archiveEntry{
+		{name: "GeoIP2-City-Test_20250101/COPYRIGHT.txt"},
+		{name: "GeoIP2-City-Test_20250101/GeoIP2-City-Test.mmdb", file: "GeoIP2-City-Test.mmdb"},
+		{name: "GeoIP2-City-Test_20250101/LICENSE.txt"},
+	}
+
+	tests := map[string][]byte{
+		"tar.gz": gzipBytes(t, tarBytes(t, entries)),
+		"tar":    tarBytes(t, entries),
+		"zip":    zipBytes(t, entries),
+	}
+
+	for name, archive := range tests {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "GeoIP2-City-Test_20250101."+name)
+			require.NoError(t, os.WriteFile(file, archive, 0o600))
+
+			reader, err := OpenArchive(file)
+			require.NoError(t, err)
+			defer reader.Close()
+
+			assert.Equal(t, "GeoIP2-City", reader.Metadata().DatabaseType)
+
+			record, err := reader.City(netip.MustParseAddr("81.2.69.160"))
+			require.NoError(t, err)
+			assert.Equal(t, "London", record.City.Names.English)
+		})
+	}
+}
+
+func TestOpenArchiveReaderErrors(t *testing.T) {
+	noDatabase := []archiveEntry{{name: "LICENSE.txt"}}
+	twoDatabases := []archiveEntry{
+		{name: "a/GeoIP2-City-Test.mmdb", file: "GeoIP2-City-Test.mmdb"},
+		{name: "b/GeoLite2-ASN-Test.mmdb", file: "GeoLite2-ASN-Test.mmdb"},
+	}
+
+	tests := []struct {
+		name    string
+		archive []byte
+		err     string
+	}{
+		{
+			name:    "tar.gz without database",
+			archive: gzipBytes(t, tarBytes(t, noDatabase)),
+			err:     "geoip2: no .mmdb file found in archive",
+		},
+		{
+			name:    "zip without database",
+			archive: zipBytes(t, noDatabase),
+			err:     "geoip2: no .mmdb file found in archive",
+		},
+		{
+			name:    "tar.gz with two databases",
+			archive: gzipBytes(t, tarBytes(t, twoDatabases)),
+			err: "geoip2: archive contains more than one .mmdb file: " +
+				"a/GeoIP2-City-Test.mmdb and b/GeoLite2-ASN-Test.mmdb",
+		},
+		{
+			name:    "zip with two databases",
+			archive: zipBytes(t, twoDatabases),
+			err: "geoip2: archive contains more than one .mmdb file: " +
+				"a/GeoIP2-City-Test.mmdb and b/GeoLite2-ASN-Test.mmdb",
+		},
+		{
+			name:    "not an archive",
+			archive: []byte("not an archive"),
+			err:     "geoip2: unsupported archive format",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := OpenArchiveReader(bytes.NewReader(test.archive))
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestOpenArchiveSizeLimit(t *testing.T) {
+	defer func(size int64) { maxArchiveDatabaseSize = size }(maxArchiveDatabaseSize)
+	maxArchiveDatabaseSize = 1024
+
+	entries := []archiveEntry{
+		{name: "GeoIP2-City-Test.mmdb", file: "GeoIP2-City-Test.mmdb"},
+	}
+	tests := map[string][]byte{
+		"tar.gz": gzipBytes(t, tarBytes(t, entries)),
+		"zip":    zipBytes(t, entries),
+	}
+
+	for name, archive := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := OpenArchiveReader(bytes.NewReader(archive))
+			require.EqualError(t, err,
+				"geoip2: archive contents exceed the maximum size of 1024 bytes")
+
+			file := filepath.Join(t.TempDir(), "GeoIP2-City-Test."+name)
+			require.NoError(t, os.WriteFile(file, archive, 0o600))
+			_, err = OpenArchive(file)
+			require.EqualError(t, err,
+				"geoip2: archive contents exceed the maximum size of 1024 bytes")
+		})
+	}
+}
+
+// entryContents returns the contents of a test archive entry: the named
+// test database, if any, or some placeholder text.
+func entryContents(t *testing.T, entry archiveEntry) []byte {
+	if entry.file == "" {
+		return []byte("placeholder for " + entry.name)
+	}
+	data, err := os.ReadFile("test-data/test-data/" + entry.file)
+	require.NoError(t, err)
+	return data
+}
+
+func tarBytes(t *testing.T, entries []archiveEntry) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		data := entryContents(t, entry)
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     entry.name,
+			Mode:     0o644,
+			Size:     int64(len(data)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err := gw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func zipBytes(t *testing.T, entries []archiveEntry) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		w, err := zw.Create(entry.name)
+		require.NoError(t, err)
+		_, err = w.Write(entryContents(t, entry))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
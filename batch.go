@@ -0,0 +1,177 @@
+package geoip2
+
+import (
+	"iter"
+	"net/netip"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// BatchResult is the result of the lookup of a single IP address by
+// BatchLookup or BatchLookupSlice.
+type BatchResult[T any] struct {
+	// Record is the record returned by the lookup.
+	Record *T
+	// Err is the error returned by the lookup, if any.
+	Err error
+	// IPAddress is the IP address looked up.
+	IPAddress netip.Addr
+}
+
+type batchOptions struct {
+	workers int
+}
+
+// BatchOption configures BatchLookup and BatchLookupSlice.
+type BatchOption func(*batchOptions)
+
+// Workers sets the number of goroutines performing lookups concurrently. The
+// default is runtime.GOMAXPROCS(0). Values less than 1 are treated as 1.
+func Workers(n int) BatchOption {
+	return func(o *batchOptions) {
+		o.workers = max(n, 1)
+	}
+}
+
+func newBatchOptions(options []BatchOption) batchOptions {
+	opts := batchOptions{workers: runtime.GOMAXPROCS(0)}
+	for _, option := range options {
+		option(&opts)
+	}
+	return opts
+}
+
+type batchJob[T any] struct {
+	result    chan BatchResult[T]
+	ipAddress netip.Addr
+}
+
+// BatchLookup looks up each of the IP addresses in ips using lookup, which
+// is typically a lookup method of a Reader or one of the other readers in
+// this package, e.g.:
+//
+//	for result := range geoip2.BatchLookup(ips, db.City) {
+//		if result.Err != nil {
+//			...
+//		}
+//		fmt.Println(result.IPAddress, result.Record.City.Names.English)
+//	}
+//
+// The lookups are performed by several goroutines concurrently. See Workers.
+// The results are yielded in the same order as the IP addresses in ips, with
+// the error of each lookup, if any, in its result. A failed lookup does not
+// stop the iteration.
+//
+// ips is consumed by a separate goroutine, which reads ahead of the results
+// yielded by a bounded number of IP addresses. If the iteration is stopped
+// early, BatchLookup returns without waiting for ips to yield its next IP
+// address, e.g., when it is blocked reading input. The goroutine stops once
+// ips yields or ends.
+func BatchLookup[T any](
+	ips iter.Seq[netip.Addr],
+	lookup func(netip.Addr) (*T, error),
+	options ...BatchOption,
+) iter.Seq[BatchResult[T]] {
+	opts := newBatchOptions(options)
+	return func(yield func(BatchResult[T]) bool) {
+		if opts.workers == 1 {
+			for ip := range ips {
+				record, err := lookup(ip)
+				if !yield(BatchResult[T]{Record: record, Err: err, IPAddress: ip}) {
+					return
+				}
+			}
+			return
+		}
+
+		done := make(chan struct{})
+		jobs := make(chan *batchJob[T])
+		// pending holds the jobs in the order of ips.
+		pending := make(chan *batchJob[T], 2*opts.workers)
+
+		// Only the workers are waited for, so that no lookup is in progress
+		// once the iteration has stopped. The goroutine reading ips is not,
+		// as ips may block, e.g., while waiting for input. It stops once ips
+		// yields its next IP address or ends.
+		var wg sync.WaitGroup
+		defer func() {
+			close(done)
+			wg.Wait()
+		}()
+
+		for range opts.workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					var job *batchJob[T]
+					select {
+					case job = <-jobs:
+					case <-done:
+						return
+					}
+					if job == nil {
+						return
+					}
+					record, err := lookup(job.ipAddress)
+					job.result <- BatchResult[T]{Record: record, Err: err, IPAddress: job.ipAddress}
+				}
+			}()
+		}
+
+		go func() {
+			defer close(pending)
+			defer close(jobs)
+			for ip := range ips {
+				job := &batchJob[T]{ipAddress: ip, result: make(chan BatchResult[T], 1)}
+				select {
+				case pending <- job:
+				case <-done:
+					return
+				}
+				select {
+				case jobs <- job:
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		for job := range pending {
+			if !yield(<-job.result) {
+				return
+			}
+		}
+	}
+}
+
+// BatchLookupSlice looks up each of the IP addresses in ips using lookup, as
+// BatchLookup does, and returns the results in the same order as ips.
+func BatchLookupSlice[T any](
+	ips []netip.Addr,
+	lookup func(netip.Addr) (*T, error),
+	options ...BatchOption,
+) []BatchResult[T] {
+	opts := newBatchOptions(options)
+	results := make([]BatchResult[T], len(ips))
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range min(opts.workers, len(ips)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				j := int(next.Add(1) - 1)
+				if j >= len(ips) {
+					return
+				}
+				record, err := lookup(ips[j])
+				results[j] = BatchResult[T]{Record: record, Err: err, IPAddress: ips[j]}
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,130 @@
+package geoip2
+
+import (
+	"net/netip"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var batchIPs = []netip.Addr{
+	netip.MustParseAddr("81.2.69.160"),
+	netip.MustParseAddr("89.160.20.112"),
+	netip.MustParseAddr("1.1.1.1"),
+	netip.MustParseAddr("216.160.83.56"),
+	netip.MustParseAddr("2001:218::"),
+	netip.MustParseAddr("81.2.69.161"),
+}
+
+func TestBatchLookup(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	for _, workers := range []int{1, 2, 8} {
+		var results []BatchResult[City]
+		for result := range BatchLookup(slices.Values(batchIPs), reader.City, Workers(workers)) {
+			results = append(results, result)
+		}
+		assertBatchResults(t, reader, results)
+
+		assertBatchResults(t, reader, BatchLookupSlice(batchIPs, reader.City, Workers(workers)))
+	}
+}
+
+func assertBatchResults(t *testing.T, reader *Reader, results []BatchResult[City]) {
+	t.Helper()
+
+	require.Len(t, results, len(batchIPs))
+	for i, result := range results {
+		assert.Equal(t, batchIPs[i], result.IPAddress)
+		require.NoError(t, result.Err)
+		expected, err := reader.City(batchIPs[i])
+		require.NoError(t, err)
+		assert.Equal(t, expected, result.Record)
+	}
+}
+
+func TestBatchLookupErrors(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	count := 0
+	for result := range BatchLookup(slices.Values(batchIPs), reader.ASN) {
+		assert.Equal(t, batchIPs[count], result.IPAddress)
+		require.ErrorAs(t, result.Err, &InvalidMethodError{})
+		count++
+	}
+	assert.Equal(t, len(batchIPs), count)
+}
+
+func TestBatchLookupBreak(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	// An endless sequence of IP addresses.
+	ips := func(yield func(netip.Addr) bool) {
+		for ip := netip.MustParseAddr("81.2.69.0"); ; ip = ip.Next() {
+			if !yield(ip) {
+				return
+			}
+		}
+	}
+
+	count := 0
+	for result := range BatchLookup(ips, reader.Country, Workers(4)) {
+		require.NoError(t, result.Err)
+		count++
+		if count == 100 {
+			break
+		}
+	}
+	assert.Equal(t, 100, count)
+}
+
+func TestBatchLookupBreakBlockedSource(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	// A sequence that blocks after its first IP address, e.g., while waiting
+	// for more input.
+	release := make(chan struct{})
+	stopped := make(chan bool, 1)
+	ips := func(yield func(netip.Addr) bool) {
+		if !yield(batchIPs[0]) {
+			stopped <- true
+			return
+		}
+		<-release
+		stopped <- !yield(batchIPs[1])
+	}
+
+	returned := make(chan struct{})
+	go func() {
+		defer close(returned)
+		for result := range BatchLookup(ips, reader.City, Workers(4)) {
+			assert.Equal(t, "London", result.Record.City.Names.English)
+			break
+		}
+	}()
+
+	select {
+	case <-returned:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for the iteration to stop")
+	}
+
+	close(release)
+	select {
+	case ok := <-stopped:
+		assert.True(t, ok)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for the sequence to stop")
+	}
+}
@@ -0,0 +1,332 @@
+package geoip2
+
+import (
+	"container/list"
+	"errors"
+	"net/netip"
+	"sync"
+
+	"github.com/oschwald/maxminddb-golang/v2"
+)
+
+// Source is a database that lookups can be performed on. It is implemented
+// by Reader, ReloadableReader, and CachingReader.
+type Source interface {
+	Enterprise(ipAddress netip.Addr) (*Enterprise, error)
+	City(ipAddress netip.Addr) (*City, error)
+	Country(ipAddress netip.Addr) (*Country, error)
+	AnonymousIP(ipAddress netip.Addr) (*AnonymousIP, error)
+	AnonymousPlus(ipAddress netip.Addr) (*AnonymousPlus, error)
+	ASN(ipAddress netip.Addr) (*ASN, error)
+	ConnectionType(ipAddress netip.Addr) (*ConnectionType, error)
+	Domain(ipAddress netip.Addr) (*Domain, error)
+	ISP(ipAddress netip.Addr) (*ISP, error)
+	Metadata() maxminddb.Metadata
+
+	// generation returns a number that changes whenever the database
+	// changes.
+	generation() uint64
+}
+
+func (*Reader) generation() uint64 {
+	return 0
+}
+
+func (r *ReloadableReader) generation() uint64 {
+	return r.swaps.Load()
+}
+
+// CacheStats contains statistics about the lookups performed by a
+// CachingReader.
+type CacheStats struct {
+	// Hits is the number of lookups served from the cache.
+	Hits uint64
+	// Misses is the number of lookups performed on the database.
+	Misses uint64
+	// Evictions is the number of records removed from the cache to make room
+	// for new ones.
+	Evictions uint64
+	// Size is the number of records currently in the cache.
+	Size int
+}
+
+type cacheKey struct {
+	network netip.Prefix
+	method  Method
+}
+
+type cacheEntry struct {
+	value any
+	key   cacheKey
+}
+
+// prefixLengths counts the cached networks of each prefix length, for IPv4
+// and IPv6 networks respectively.
+type prefixLengths [2][129]int
+
+// CachingReader caches the records returned by a Source. As all IP addresses
+// within the Network of a record share the same data, a record is cached for
+// its Network rather than for the IP address looked up. Any later lookup of
+// an IP address within that network is served from the cache.
+//
+// The cache holds a bounded number of records, evicting the least recently
+// used record when full. If the Source is a ReloadableReader, the cache is
+// cleared whenever the database is reloaded.
+//
+// The structs returned from the cache are copies of the cached records with
+// IPAddress set to the IP address looked up. However, they share the memory
+// referenced by slices and pointers, e.g., Subdivisions, with the cached
+// record, so this memory must not be modified.
+type CachingReader struct {
+	source  Source
+	lru     *list.List
+	entries map[cacheKey]*list.Element
+	lengths map[Method]*prefixLengths
+	stats   CacheStats
+	gen     uint64
+	size    int
+	mu      sync.Mutex
+}
+
+// NewCachingReader returns a CachingReader that caches up to size records
+// returned by source. The CachingReader does not take ownership of source,
+// which must be closed by the caller once it is no longer in use.
+func NewCachingReader(source Source, size int) (*CachingReader, error) {
+	if size <= 0 {
+		return nil, errors.New("geoip2: the cache size must be positive")
+	}
+	return &CachingReader{
+		source:  source,
+		lru:     list.New(),
+		entries: map[cacheKey]*list.Element{},
+		lengths: map[Method]*prefixLengths{},
+		gen:     source.generation(),
+		size:    size,
+	}, nil
+}
+
+// Stats returns statistics about the lookups performed by the CachingReader.
+func (c *CachingReader) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Size = c.lru.Len()
+	return stats
+}
+
+// Purge removes all records from the cache.
+func (c *CachingReader) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.purgeLocked()
+}
+
+func (c *CachingReader) purgeLocked() {
+	c.lru.Init()
+	clear(c.entries)
+	clear(c.lengths)
+}
+
+func familyIndex(ip netip.Addr) int {
+	if ip.Is4() {
+		return 0
+	}
+	return 1
+}
+
+// syncGenerationLocked clears the cache if the database has changed since it
+// was filled. gen is the generation observed by the caller. It returns false
+// if gen is older than that of the cache, in which case the caller must not
+// use the cache.
+func (c *CachingReader) syncGenerationLocked(gen uint64) bool {
+	if gen > c.gen {
+		c.purgeLocked()
+		c.gen = gen
+	}
+	return gen == c.gen
+}
+
+func (c *CachingReader) get(gen uint64, method Method, ip netip.Addr) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.syncGenerationLocked(gen) {
+		c.stats.Misses++
+		return nil, false
+	}
+
+	if lengths, ok := c.lengths[method]; ok {
+		counts := &lengths[familyIndex(ip)]
+		// The networks in a database do not overlap, so at most one of
+		// these can match.
+		for bits := ip.BitLen(); bits >= 0; bits-- {
+			if counts[bits] == 0 {
+				continue
+			}
+			network, err := ip.Prefix(bits)
+			if err != nil {
+				continue
+			}
+			if elem, ok := c.entries[cacheKey{network: network, method: method}]; ok {
+				c.lru.MoveToFront(elem)
+				c.stats.Hits++
+				return elem.Value.(*cacheEntry).value, true
+			}
+		}
+	}
+	c.stats.Misses++
+	return nil, false
+}
+
+func (c *CachingReader) add(gen uint64, method Method, network netip.Prefix, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !network.IsValid() || !c.syncGenerationLocked(gen) {
+		return
+	}
+	key := cacheKey{network: network, method: method}
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+
+	if c.lru.Len() >= c.size {
+		oldest := c.lru.Back()
+		oldestKey := oldest.Value.(*cacheEntry).key
+		c.lru.Remove(oldest)
+		delete(c.entries, oldestKey)
+		c.lengths[oldestKey.method][familyIndex(oldestKey.network.Addr())][oldestKey.network.Bits()]--
+		c.stats.Evictions++
+	}
+
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, value: value})
+	lengths, ok := c.lengths[method]
+	if !ok {
+		lengths = &prefixLengths{}
+		c.lengths[method] = lengths
+	}
+	lengths[familyIndex(network.Addr())][network.Bits()]++
+}
+
+// cachedLookup performs a lookup using the cache. fields returns pointers to
+// the IPAddress and Network fields of a record.
+func cachedLookup[T any](
+	c *CachingReader,
+	ipAddress netip.Addr,
+	method Method,
+	lookup func(netip.Addr) (*T, error),
+	fields func(*T) (*netip.Addr, *netip.Prefix),
+) (*T, error) {
+	gen := c.source.generation()
+	if value, ok := c.get(gen, method, ipAddress); ok {
+		record := *value.(*T)
+		ip, _ := fields(&record)
+		*ip = ipAddress
+		return &record, nil
+	}
+
+	record, err := lookup(ipAddress)
+	if err != nil {
+		return record, err
+	}
+	// A copy is cached so that the caller may modify the returned struct.
+	cached := *record
+	_, network := fields(&cached)
+	c.add(gen, method, *network, &cached)
+	return record, nil
+}
+
+// Enterprise takes an IP address as a netip.Addr and returns an Enterprise
+// struct and/or an error, using the cache when possible. See
+// Reader.Enterprise.
+func (c *CachingReader) Enterprise(ipAddress netip.Addr) (*Enterprise, error) {
+	return cachedLookup(c, ipAddress, MethodEnterprise, c.source.Enterprise,
+		func(r *Enterprise) (*netip.Addr, *netip.Prefix) {
+			return &r.Traits.IPAddress, &r.Traits.Network
+		})
+}
+
+// City takes an IP address as a netip.Addr and returns a City struct and/or
+// an error, using the cache when possible. See Reader.City.
+func (c *CachingReader) City(ipAddress netip.Addr) (*City, error) {
+	return cachedLookup(c, ipAddress, MethodCity, c.source.City,
+		func(r *City) (*netip.Addr, *netip.Prefix) {
+			return &r.Traits.IPAddress, &r.Traits.Network
+		})
+}
+
+// Country takes an IP address as a netip.Addr and returns a Country struct
+// and/or an error, using the cache when possible. See Reader.Country.
+func (c *CachingReader) Country(ipAddress netip.Addr) (*Country, error) {
+	return cachedLookup(c, ipAddress, MethodCountry, c.source.Country,
+		func(r *Country) (*netip.Addr, *netip.Prefix) {
+			return &r.Traits.IPAddress, &r.Traits.Network
+		})
+}
+
+// AnonymousIP takes an IP address as a netip.Addr and returns a AnonymousIP
+// struct and/or an error, using the cache when possible. See
+// Reader.AnonymousIP.
+func (c *CachingReader) AnonymousIP(ipAddress netip.Addr) (*AnonymousIP, error) {
+	return cachedLookup(c, ipAddress, MethodAnonymousIP, c.source.AnonymousIP,
+		func(r *AnonymousIP) (*netip.Addr, *netip.Prefix) {
+			return &r.IPAddress, &r.Network
+		})
+}
+
+// AnonymousPlus takes an IP address as a netip.Addr and returns a
+// AnonymousPlus struct and/or an error, using the cache when possible. See
+// Reader.AnonymousPlus.
+func (c *CachingReader) AnonymousPlus(ipAddress netip.Addr) (*AnonymousPlus, error) {
+	return cachedLookup(c, ipAddress, MethodAnonymousPlus, c.source.AnonymousPlus,
+		func(r *AnonymousPlus) (*netip.Addr, *netip.Prefix) {
+			return &r.IPAddress, &r.Network
+		})
+}
+
+// ASN takes an IP address as a netip.Addr and returns a ASN struct and/or an
+// error, using the cache when possible. See Reader.ASN.
+func (c *CachingReader) ASN(ipAddress netip.Addr) (*ASN, error) {
+	return cachedLookup(c, ipAddress, MethodASN, c.source.ASN,
+		func(r *ASN) (*netip.Addr, *netip.Prefix) {
+			return &r.IPAddress, &r.Network
+		})
+}
+
+// ConnectionType takes an IP address as a netip.Addr and returns a
+// ConnectionType struct and/or an error, using the cache when possible. See
+// Reader.ConnectionType.
+func (c *CachingReader) ConnectionType(ipAddress netip.Addr) (*ConnectionType, error) {
+	return cachedLookup(c, ipAddress, MethodConnectionType, c.source.ConnectionType,
+		func(r *ConnectionType) (*netip.Addr, *netip.Prefix) {
+			return &r.IPAddress, &r.Network
+		})
+}
+
+// Domain takes an IP address as a netip.Addr and returns a Domain struct
+// and/or an error, using the cache when possible. See Reader.Domain.
+func (c *CachingReader) Domain(ipAddress netip.Addr) (*Domain, error) {
+	return cachedLookup(c, ipAddress, MethodDomain, c.source.Domain,
+		func(r *Domain) (*netip.Addr, *netip.Prefix) {
+			return &r.IPAddress, &r.Network
+		})
+}
+
+// ISP takes an IP address as a netip.Addr and returns a ISP struct and/or an
+// error, using the cache when possible. See Reader.ISP.
+func (c *CachingReader) ISP(ipAddress netip.Addr) (*ISP, error) {
+	return cachedLookup(c, ipAddress, MethodISP, c.source.ISP,
+		func(r *ISP) (*netip.Addr, *netip.Prefix) {
+			return &r.IPAddress, &r.Network
+		})
+}
+
+// Metadata takes no arguments and returns a struct containing metadata about
+// the MaxMind database in use by the source of the CachingReader.
+func (c *CachingReader) Metadata() maxminddb.Metadata {
+	return c.source.Metadata()
+}
+
+func (c *CachingReader) generation() uint64 {
+	return c.source.generation()
+}
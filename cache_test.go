@@ -0,0 +1,111 @@
+package geoip2
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachingReader(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	cache, err := NewCachingReader(reader, 10)
+	require.NoError(t, err)
+
+	testAddr := netip.MustParseAddr("81.2.69.160")
+	record, err := cache.City(testAddr)
+	require.NoError(t, err)
+	assert.Equal(t, "London", record.City.Names.English)
+	assert.Equal(t, CacheStats{Misses: 1, Size: 1}, cache.Stats())
+
+	// Modifying the returned record must not affect the cache.
+	record.City.Names.English = "Paris"
+
+	network := record.Traits.Network
+	otherAddr := network.Addr().Next()
+	require.NotEqual(t, testAddr, otherAddr)
+
+	cached, err := cache.City(otherAddr)
+	require.NoError(t, err)
+	expected, err := reader.City(otherAddr)
+	require.NoError(t, err)
+	assert.Equal(t, expected, cached)
+	assert.Equal(t, otherAddr, cached.Traits.IPAddress)
+	assert.Equal(t, network, cached.Traits.Network)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1, Size: 1}, cache.Stats())
+
+	// Records are cached separately for each method.
+	country, err := cache.Country(testAddr)
+	require.NoError(t, err)
+	assert.Equal(t, "GB", country.Country.ISOCode)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 2, Size: 2}, cache.Stats())
+
+	_, err = cache.ASN(testAddr)
+	require.ErrorAs(t, err, &InvalidMethodError{})
+	assert.Equal(t, 2, cache.Stats().Size)
+
+	cache.Purge()
+	assert.Equal(t, 0, cache.Stats().Size)
+}
+
+func TestCachingReaderEviction(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	cache, err := NewCachingReader(reader, 1)
+	require.NoError(t, err)
+
+	london := netip.MustParseAddr("81.2.69.160")
+	linkoping := netip.MustParseAddr("89.160.20.112")
+
+	_, err = cache.City(london)
+	require.NoError(t, err)
+	record, err := cache.City(linkoping)
+	require.NoError(t, err)
+	assert.Equal(t, "Linköping", record.City.Names.English)
+	assert.Equal(t, CacheStats{Misses: 2, Evictions: 1, Size: 1}, cache.Stats())
+
+	record, err = cache.City(london)
+	require.NoError(t, err)
+	assert.Equal(t, "London", record.City.Names.English)
+	assert.Equal(t, CacheStats{Misses: 3, Evictions: 2, Size: 1}, cache.Stats())
+}
+
+func TestCachingReaderReload(t *testing.T) {
+	reader, err := OpenReloadable("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	cache, err := NewCachingReader(reader, 10)
+	require.NoError(t, err)
+
+	testAddr := netip.MustParseAddr("81.2.69.160")
+	_, err = cache.City(testAddr)
+	require.NoError(t, err)
+	_, err = cache.City(testAddr)
+	require.NoError(t, err)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1, Size: 1}, cache.Stats())
+
+	require.NoError(t, reader.ReloadFrom("test-data/test-data/GeoIP2-Enterprise-Test.mmdb"))
+
+	record, err := cache.City(testAddr)
+	require.NoError(t, err)
+	expected, err := reader.City(testAddr)
+	require.NoError(t, err)
+	assert.Equal(t, expected, record)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 2, Size: 1}, cache.Stats())
+}
+
+func TestNewCachingReaderInvalidSize(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	_, err = NewCachingReader(reader, 0)
+	require.Error(t, err)
+}
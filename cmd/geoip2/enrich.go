@@ -0,0 +1,389 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/oschwald/geoip2-golang/v2"
+)
+
+// databasesFlag is the value of the repeatable -db flag of enrich.
+type databasesFlag []string
+
+func (d *databasesFlag) String() string {
+	return strings.Join(*d, ",")
+}
+
+func (d *databasesFlag) Set(file string) error {
+	*d = append(*d, file)
+	return nil
+}
+
+// enricher looks up the IP addresses of the input in several databases and
+// returns the selected fields of their records.
+type enricher struct {
+	readers []*geoip2.Reader
+	fields  []string
+	stderr  io.Writer
+}
+
+// values returns the values of the fields for the IP address s, taking each
+// field from the first database whose record has it. Fields without a value,
+// e.g., because s is not a valid IP address, are nil. line identifies the
+// input in warnings.
+func (e *enricher) values(s string, line int) ([]any, error) {
+	ip, err := geoip2.ParseAddr(s)
+	if err != nil {
+		return e.warn(line, err), nil
+	}
+	values := make([]any, len(e.fields))
+	for _, reader := range e.readers {
+		record, err := lookup(reader, ip)
+		if err != nil {
+			return nil, err
+		}
+		fields, err := geoip2.FlattenRecord(record)
+		if err != nil {
+			return nil, err
+		}
+		for i, field := range e.fields {
+			if values[i] == nil {
+				values[i] = fields[field]
+			}
+		}
+	}
+	return values, nil
+}
+
+// checkFields returns an error if any of the fields cannot be provided by
+// the records of the databases.
+func (e *enricher) checkFields() error {
+	var unknown []string
+	for _, field := range e.fields {
+		path := strings.Split(field, ".")
+		if !slices.ContainsFunc(e.readers, func(reader *geoip2.Reader) bool {
+			t, ok := recordTypes[reader.Method()]
+			return ok && hasField(t, path)
+		}) {
+			unknown = append(unknown, strconv.Quote(field))
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("geoip2: no database provides the fields %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// recordTypes are the types of the records returned by lookup for each
+// method.
+var recordTypes = map[geoip2.Method]reflect.Type{
+	geoip2.MethodEnterprise:     reflect.TypeFor[geoip2.Enterprise](),
+	geoip2.MethodCity:           reflect.TypeFor[geoip2.City](),
+	geoip2.MethodISP:            reflect.TypeFor[geoip2.ISP](),
+	geoip2.MethodASN:            reflect.TypeFor[geoip2.ASN](),
+	geoip2.MethodAnonymousPlus:  reflect.TypeFor[geoip2.AnonymousPlus](),
+	geoip2.MethodAnonymousIP:    reflect.TypeFor[geoip2.AnonymousIP](),
+	geoip2.MethodConnectionType: reflect.TypeFor[geoip2.ConnectionType](),
+	geoip2.MethodDomain:         reflect.TypeFor[geoip2.Domain](),
+}
+
+var namesType = reflect.TypeFor[geoip2.Names]()
+
+// hasField reports whether path is the path of a field that FlattenRecord may
+// return for a record of type t. The path of a Names field may end with any
+// locale.
+func hasField(t reflect.Type, path []string) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == namesType:
+		return len(path) == 1 && path[0] != ""
+	case t.Kind() == reflect.Struct && t.PkgPath() == namesType.PkgPath():
+		if len(path) == 0 {
+			return false
+		}
+		for i := range t.NumField() {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if field.IsExported() && name == path[0] && name != "-" {
+				return hasField(field.Type, path[1:])
+			}
+		}
+		return false
+	case t.Kind() == reflect.Slice:
+		if len(path) == 0 {
+			return false
+		}
+		i, err := strconv.Atoi(path[0])
+		return err == nil && i >= 0 && hasField(t.Elem(), path[1:])
+	default:
+		// Other types, e.g., netip.Prefix, are encoded as a single value.
+		return len(path) == 0
+	}
+}
+
+// warn reports err for the input at line and returns the values of the
+// fields for it, all nil.
+func (e *enricher) warn(line int, err error) []any {
+	fmt.Fprintf(e.stderr, "line %d: %v\n", line, err)
+	return make([]any, len(e.fields))
+}
+
+// runEnrich runs the enrich subcommand with args and returns its exit status.
+func runEnrich(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("geoip2 enrich", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: geoip2 enrich -db <file> [-db <file>]... "+
+			"[-input csv|jsonl] [-ip <name>] -fields <field>,...")
+		flags.PrintDefaults()
+	}
+	var files databasesFlag
+	flags.Var(&files, "db", "a database `file` to look up IP addresses in; may be repeated")
+	input := flags.String("input", "csv", "the `format` of the input and output: csv or jsonl")
+	ipField := flags.String("ip", "ip", "the `name` of the column or field holding the IP address")
+	fieldList := flags.String("fields", "",
+		"the comma-separated flattened `fields` to append, e.g., country.iso_code")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if len(files) == 0 || *fieldList == "" || flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	var enrich func(*enricher, io.Reader, io.Writer, string) error
+	switch *input {
+	case "csv":
+		enrich = enrichCSV
+	case "jsonl":
+		enrich = enrichJSONL
+	default:
+		fmt.Fprintf(stderr, "geoip2: unknown input format %q\n", *input)
+		return 2
+	}
+
+	e := &enricher{stderr: stderr}
+	for field := range strings.SplitSeq(*fieldList, ",") {
+		e.fields = append(e.fields, strings.TrimSpace(field))
+	}
+	for _, file := range files {
+		reader, err := geoip2.Open(file)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		defer reader.Close()
+		e.readers = append(e.readers, reader)
+	}
+	if err := e.checkFields(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	if err := enrich(e, stdin, stdout, *ipField); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// enrichCSV copies the CSV records of r, which must begin with a header
+// record, to w, appending a column for each field.
+func enrichCSV(e *enricher, r io.Reader, w io.Writer, ipField string) error {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+	writer := csv.NewWriter(w)
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	column := -1
+	for i, name := range header {
+		if name == ipField {
+			column = i
+			break
+		}
+	}
+	if column < 0 {
+		return fmt.Errorf("geoip2: the CSV header has no %q column", ipField)
+	}
+	if err := writer.Write(append(header, e.fields...)); err != nil {
+		return err
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := reader.FieldPos(column)
+		values, err := e.values(record[column], line)
+		if err != nil {
+			return err
+		}
+		for _, value := range values {
+			record = append(record, csvValue(value))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func csvValue(value any) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// enrichJSONL copies the JSON objects of r, one per line, to w, adding a
+// member for each field. The other members of the input are left as they
+// are, while those named like a field are replaced. Fields without a value
+// are null.
+func enrichJSONL(e *enricher, r io.Reader, w io.Writer, ipField string) error {
+	reader := bufio.NewReader(r)
+	writer := bufio.NewWriter(w)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if len(data) == 0 && errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+
+		members, err := readObject(data)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		var values []any
+		ip, err := stringMember(members, ipField)
+		if err != nil {
+			values = e.warn(line, err)
+		} else if values, err = e.values(ip, line); err != nil {
+			return err
+		}
+
+		if err := writeObject(writer, members, e.fields, values); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// member is a member of a JSON object.
+type member struct {
+	name  string
+	value json.RawMessage
+}
+
+// readObject returns the members of the JSON object data, in order. It
+// returns an error if data is not a single JSON object.
+func readObject(data []byte) ([]member, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil {
+		return nil, err
+	} else if token != json.Delim('{') {
+		return nil, errors.New("geoip2: the input is not a JSON object")
+	}
+	var members []member
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		m := member{name: token.(string)}
+		if err := decoder.Decode(&m.value); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("geoip2: unexpected data after the JSON object")
+	}
+	return members, nil
+}
+
+// stringMember returns the string value of the member of members named name.
+// A missing or null member is the empty string; any other value that is not a
+// string is an error.
+func stringMember(members []member, name string) (string, error) {
+	var s string
+	for _, m := range members {
+		if m.name != name {
+			continue
+		}
+		if err := json.Unmarshal(m.value, &s); err != nil {
+			return "", fmt.Errorf("geoip2: the %q field is not a string", name)
+		}
+	}
+	return s, nil
+}
+
+// writeObject writes a line with a JSON object of members, without those
+// named like one of fields, followed by a member for each of fields with its
+// value in values.
+func writeObject(w *bufio.Writer, members []member, fields []string, values []any) error {
+	data := []byte{'{'}
+	for _, m := range members {
+		if slices.Contains(fields, m.name) {
+			continue
+		}
+		if len(data) > 1 {
+			data = append(data, ',')
+		}
+		name, err := json.Marshal(m.name)
+		if err != nil {
+			return err
+		}
+		data = append(data, name...)
+		data = append(data, ':')
+		data = append(data, m.value...)
+	}
+	for i, field := range fields {
+		if len(data) > 1 {
+			data = append(data, ',')
+		}
+		name, err := json.Marshal(field)
+		if err != nil {
+			return err
+		}
+		value, err := json.Marshal(values[i])
+		if err != nil {
+			return err
+		}
+		data = fmt.Appendf(data, "%s:%s", name, value)
+	}
+	data = append(data, "}\n"...)
+	_, err := w.Write(data)
+	return err
+}
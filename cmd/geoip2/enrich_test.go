@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runEnrichCommand(input string, args ...string) (stdout, stderr string, status int) {
+	var out, errOut bytes.Buffer
+	status = run(append([]string{"enrich"}, args...), strings.NewReader(input), &out, &errOut)
+	return out.String(), errOut.String(), status
+}
+
+func TestEnrichCSV(t *testing.T) {
+	input := strings.Join([]string{
+		"time,client,path",
+		"1,81.2.69.160,/",
+		`2,1.128.0.0,"/a,b"`,
+		"3,bogus,/",
+		"",
+	}, "\n")
+	stdout, stderr, status := runEnrichCommand(input,
+		"-db", testData+"GeoIP2-City-Test.mmdb",
+		"-db", testData+"GeoLite2-ASN-Test.mmdb",
+		"-ip", "client",
+		"-fields", "country.iso_code,city.names.en,autonomous_system_number",
+	)
+	require.Equal(t, 0, status, stderr)
+	assert.Equal(t, strings.Join([]string{
+		"time,client,path,country.iso_code,city.names.en,autonomous_system_number",
+		"1,81.2.69.160,/,GB,London,",
+		`2,1.128.0.0,"/a,b",,,1221`,
+		"3,bogus,/,,,",
+		"",
+	}, "\n"), stdout)
+	assert.Contains(t, stderr, "line 4: geoip2: invalid IP address")
+}
+
+func TestEnrichCSVMissingColumn(t *testing.T) {
+	_, stderr, status := runEnrichCommand("a,b\n1,2\n",
+		"-db", testData+"GeoIP2-City-Test.mmdb",
+		"-fields", "country.iso_code",
+	)
+	assert.Equal(t, 1, status)
+	assert.Contains(t, stderr, `the CSV header has no "ip" column`)
+
+	stdout, stderr, status := runEnrichCommand("",
+		"-db", testData+"GeoIP2-City-Test.mmdb",
+		"-fields", "country.iso_code",
+	)
+	assert.Equal(t, 0, status, stderr)
+	assert.Empty(t, stdout)
+}
+
+func TestEnrichJSONL(t *testing.T) {
+	input := strings.Join([]string{
+		`{"z":1,"ip":"81.2.69.160","a":{"b":[1]}}`,
+		"",
+		`{"ip":"1.128.0.0"}`,
+		`{}`,
+	}, "\n")
+	stdout, stderr, status := runEnrichCommand(input,
+		"-db", testData+"GeoIP2-City-Test.mmdb",
+		"-db", testData+"GeoLite2-ASN-Test.mmdb",
+		"-input", "jsonl",
+		"-fields", "country.iso_code,autonomous_system_number",
+	)
+	require.Equal(t, 0, status, stderr)
+	assert.Equal(t, strings.Join([]string{
+		`{"z":1,"ip":"81.2.69.160","a":{"b":[1]},"country.iso_code":"GB","autonomous_system_number":null}`,
+		`{"ip":"1.128.0.0","country.iso_code":null,"autonomous_system_number":1221}`,
+		`{"country.iso_code":null,"autonomous_system_number":null}`,
+		"",
+	}, "\n"), stdout)
+	assert.Contains(t, stderr, "line 4: geoip2: invalid IP address")
+
+	// Members named like a field are replaced, and IP fields that are not
+	// strings are reported like invalid IP addresses.
+	input = strings.Join([]string{
+		`{"ip":"81.2.69.160","country.iso_code":"XX","n":1}`,
+		`{"ip":1}`,
+		`{"ip":null}`,
+	}, "\n")
+	stdout, stderr, status = runEnrichCommand(input,
+		"-db", testData+"GeoIP2-City-Test.mmdb",
+		"-input", "jsonl",
+		"-fields", "country.iso_code",
+	)
+	require.Equal(t, 0, status, stderr)
+	assert.Equal(t, strings.Join([]string{
+		`{"ip":"81.2.69.160","n":1,"country.iso_code":"GB"}`,
+		`{"ip":1,"country.iso_code":null}`,
+		`{"ip":null,"country.iso_code":null}`,
+		"",
+	}, "\n"), stdout)
+	assert.Contains(t, stderr, `line 2: geoip2: the "ip" field is not a string`)
+	assert.Contains(t, stderr, "line 3: geoip2: invalid IP address")
+
+	for _, input := range []string{"[1]\n", "null\n", `"ip"`, "{} {}\n", `{"ip":`} {
+		stdout, stderr, status = runEnrichCommand(input,
+			"-db", testData+"GeoIP2-City-Test.mmdb",
+			"-input", "jsonl",
+			"-fields", "country.iso_code",
+		)
+		assert.Equal(t, 1, status, input)
+		assert.Empty(t, stdout, input)
+		assert.Contains(t, stderr, "line 1:", input)
+	}
+}
+
+func TestEnrichUsage(t *testing.T) {
+	_, _, status := runEnrichCommand("", "-fields", "country.iso_code")
+	assert.Equal(t, 2, status)
+
+	_, _, status = runEnrichCommand("", "-db", testData+"GeoIP2-City-Test.mmdb")
+	assert.Equal(t, 2, status)
+
+	_, stderr, status := runEnrichCommand("",
+		"-db", testData+"GeoIP2-City-Test.mmdb",
+		"-fields", "country.iso_code",
+		"-input", "xml",
+	)
+	assert.Equal(t, 2, status)
+	assert.Contains(t, stderr, `unknown input format "xml"`)
+}
+
+func TestEnrichFields(t *testing.T) {
+	stdout, stderr, status := runEnrichCommand("ip\n81.2.69.160\n",
+		"-db", testData+"GeoIP2-City-Test.mmdb",
+		"-fields", "country.iso_code, city.names.en , subdivisions.0.iso_code",
+	)
+	require.Equal(t, 0, status, stderr)
+	assert.Equal(t,
+		"ip,country.iso_code,city.names.en,subdivisions.0.iso_code\n81.2.69.160,GB,London,ENG\n",
+		stdout,
+	)
+
+	_, stderr, status = runEnrichCommand("ip\n81.2.69.160\n",
+		"-db", testData+"GeoIP2-City-Test.mmdb",
+		"-fields", "country.iso_code,contry.iso_code,autonomous_system_number,city.names",
+	)
+	assert.Equal(t, 2, status)
+	assert.Equal(t,
+		`geoip2: no database provides the fields "contry.iso_code", `+
+			`"autonomous_system_number", "city.names"`+"\n",
+		stderr,
+	)
+}
@@ -0,0 +1,170 @@
+// Command geoip2 looks up IP addresses in a GeoIP2 or GeoLite2 database and
+// prints the records found for them.
+//
+// Usage:
+//
+//	geoip2 -db <file> [-format json|table] <ip>...
+//	geoip2 enrich -db <file> [-db <file>]... [-input csv|jsonl] [-ip <name>] -fields <field>,...
+//
+// The type of the database is detected from its metadata, and the most
+// detailed record it provides is printed, e.g., the Enterprise record for an
+// Enterprise database and the City record for a City or Country database.
+//
+// With -format json, the default, each record is printed as an indented JSON
+// object, as accepted by tools such as jq. With -format table, the fields of
+// each record are printed as aligned rows of flattened keys, such as
+// "country.iso_code", and values.
+//
+// An error is reported for addresses that are not in the database, and the
+// exit status is 1 if any lookup fails.
+//
+// The enrich subcommand reads CSV, with a header row, or JSON Lines from the
+// standard input and writes it to the standard output with the given fields
+// appended to each row or object, e.g.:
+//
+//	geoip2 enrich -db GeoIP2-City.mmdb -db GeoLite2-ASN.mmdb -ip client_ip \
+//		-fields country.iso_code,autonomous_system_number < access.csv
+//
+// The IP address is taken from the column or top-level field named by -ip. The
+// fields are the flattened keys printed with -format table, and each is taken
+// from the first database whose record has it. Fields that none of the
+// databases can provide are reported as an error. Fields without a value are
+// left empty in CSV and are null in JSON Lines. Invalid IP addresses,
+// including JSON values that are not strings, are reported on the standard
+// error, and their fields are left without a value. Each line of JSON Lines
+// must be an object, whose members named like a field are replaced.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/oschwald/geoip2-golang/v2"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command with args and returns its exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) > 0 && args[0] == "enrich" {
+		return runEnrich(args[1:], stdin, stdout, stderr)
+	}
+
+	flags := flag.NewFlagSet("geoip2", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: geoip2 -db <file> [-format json|table] <ip>...")
+		flags.PrintDefaults()
+	}
+	file := flags.String("db", "", "the database `file` to look up IP addresses in")
+	format := flags.String("format", "json", "the output `format`: json or table")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *file == "" || flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	var print func(io.Writer, any) error
+	switch *format {
+	case "json":
+		print = printJSON
+	case "table":
+		print = printTable
+	default:
+		fmt.Fprintf(stderr, "geoip2: unknown format %q\n", *format)
+		return 2
+	}
+
+	reader, err := geoip2.Open(*file, geoip2.StrictLookups())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	defer reader.Close()
+
+	status := 0
+	printed := false
+	for _, arg := range flags.Args() {
+		ip, err := geoip2.ParseAddr(arg)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			status = 1
+			continue
+		}
+		record, err := lookup(reader, ip)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			status = 1
+			continue
+		}
+		if printed && *format == "table" {
+			fmt.Fprintln(stdout)
+		}
+		printed = true
+		if err := print(stdout, record); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+	return status
+}
+
+// lookup looks up ip with the Reader method for the most detailed record type
+// provided by the database.
+func lookup(reader *geoip2.Reader, ip netip.Addr) (any, error) {
+	switch reader.Method() {
+	case geoip2.MethodEnterprise:
+		return reader.Enterprise(ip)
+	case geoip2.MethodCity:
+		return reader.City(ip)
+	case geoip2.MethodISP:
+		return reader.ISP(ip)
+	case geoip2.MethodASN:
+		return reader.ASN(ip)
+	case geoip2.MethodAnonymousPlus:
+		return reader.AnonymousPlus(ip)
+	case geoip2.MethodAnonymousIP:
+		return reader.AnonymousIP(ip)
+	case geoip2.MethodConnectionType:
+		return reader.ConnectionType(ip)
+	case geoip2.MethodDomain:
+		return reader.Domain(ip)
+	default:
+		return nil, errors.New("geoip2: unsupported database type " + reader.Metadata().DatabaseType)
+	}
+}
+
+func printJSON(w io.Writer, record any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(record)
+}
+
+func printTable(w io.Writer, record any) error {
+	fields, err := geoip2.FlattenRecord(record)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, key := range keys {
+		fmt.Fprintf(table, "%s\t%v\n", key, fields[key])
+	}
+	return table.Flush()
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testData = "../../test-data/test-data/"
+
+func runCommand(args ...string) (stdout, stderr string, status int) {
+	var out, errOut bytes.Buffer
+	status = run(args, strings.NewReader(""), &out, &errOut)
+	return out.String(), errOut.String(), status
+}
+
+func TestRunJSON(t *testing.T) {
+	stdout, stderr, status := runCommand(
+		"-db", testData+"GeoIP2-City-Test.mmdb",
+		"81.2.69.160", "2001:218::1",
+	)
+	require.Equal(t, 0, status, stderr)
+
+	decoder := json.NewDecoder(strings.NewReader(stdout))
+	var records []map[string]any
+	for decoder.More() {
+		var record map[string]any
+		require.NoError(t, decoder.Decode(&record))
+		records = append(records, record)
+	}
+	require.Len(t, records, 2)
+	assert.Equal(t, "GB", records[0]["country"].(map[string]any)["iso_code"])
+	assert.Equal(t, "JP", records[1]["country"].(map[string]any)["iso_code"])
+}
+
+func TestRunTable(t *testing.T) {
+	stdout, stderr, status := runCommand(
+		"-db", testData+"GeoLite2-ASN-Test.mmdb",
+		"-format", "table",
+		"1.128.0.0",
+	)
+	require.Equal(t, 0, status, stderr)
+	assert.Equal(t, strings.Join([]string{
+		"autonomous_system_number        1221",
+		"autonomous_system_organization  Telstra Pty Ltd",
+		"ip_address                      1.128.0.0",
+		"network                         1.128.0.0/11",
+		"",
+	}, "\n"), stdout)
+}
+
+func TestRunDetectsDatabaseType(t *testing.T) {
+	tests := map[string]string{
+		"GeoIP2-Enterprise-Test.mmdb":      "traits.user_type",
+		"GeoIP2-Connection-Type-Test.mmdb": "connection_type",
+		"GeoIP2-Domain-Test.mmdb":          "domain",
+		"GeoIP2-ISP-Test.mmdb":             "isp",
+		"GeoIP2-Anonymous-IP-Test.mmdb":    "is_anonymous",
+	}
+	ips := map[string]string{
+		"GeoIP2-Enterprise-Test.mmdb":      "74.209.24.0",
+		"GeoIP2-Connection-Type-Test.mmdb": "1.0.1.0",
+		"GeoIP2-Domain-Test.mmdb":          "1.2.0.0",
+		"GeoIP2-ISP-Test.mmdb":             "1.128.0.0",
+		"GeoIP2-Anonymous-IP-Test.mmdb":    "1.2.0.0",
+	}
+	for file, key := range tests {
+		stdout, stderr, status := runCommand("-db", testData+file, "-format", "table", ips[file])
+		require.Equal(t, 0, status, "%s: %s", file, stderr)
+		assert.Contains(t, stdout, key, file)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	stdout, stderr, status := runCommand(
+		"-db", testData+"GeoIP2-City-Test.mmdb",
+		"not-an-ip", "10.0.0.1", "81.2.69.160",
+	)
+	assert.Equal(t, 1, status)
+	assert.Contains(t, stderr, `geoip2: invalid IP address`)
+	assert.Contains(t, stderr, "10.0.0.1")
+	assert.Contains(t, stdout, `"London"`)
+
+	_, _, status = runCommand("81.2.69.160")
+	assert.Equal(t, 2, status)
+
+	_, stderr, status = runCommand("-db", testData+"GeoIP2-City-Test.mmdb", "-format", "xml", "1.1.1.1")
+	assert.Equal(t, 2, status)
+	assert.Contains(t, stderr, `unknown format "xml"`)
+
+	_, stderr, status = runCommand("-db", "missing.mmdb", "1.1.1.1")
+	assert.Equal(t, 1, status)
+	assert.NotEmpty(t, stderr)
+}
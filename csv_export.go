@@ -0,0 +1,308 @@
+package geoip2
+
+import (
+	"cmp"
+	"encoding/csv"
+	"io"
+	"iter"
+	"maps"
+	"net/netip"
+	"slices"
+	"strconv"
+
+	"github.com/oschwald/maxminddb-golang/v2"
+)
+
+// CSVWriters are the destinations of ExportCityCSV and ExportCountryCSV, which
+// write the database in the layout of the GeoIP2 and GeoLite2 CSV databases.
+type CSVWriters struct {
+	// BlocksIPv4 receives the IPv4 networks and the GeoNameIDs of their
+	// locations, as in GeoLite2-City-Blocks-IPv4.csv. The IPv4 networks are
+	// skipped if it is nil.
+	BlocksIPv4 io.Writer
+	// BlocksIPv6 receives the IPv6 networks, as in
+	// GeoLite2-City-Blocks-IPv6.csv. The IPv6 networks are skipped if it is
+	// nil.
+	BlocksIPv6 io.Writer
+	// Locations receives, for each locale, e.g., "en", the locations
+	// referenced by the networks, with their names in that locale, as in
+	// GeoLite2-City-Locations-en.csv. The locations are written once all of
+	// the networks have been written.
+	Locations map[string]io.Writer
+}
+
+var (
+	cityBlocksHeader = []string{
+		"network", "geoname_id", "registered_country_geoname_id",
+		"represented_country_geoname_id", "is_anonymous_proxy",
+		"is_satellite_provider", "postal_code", "latitude", "longitude",
+		"accuracy_radius", "is_anycast",
+	}
+	cityLocationsHeader = []string{
+		"geoname_id", "locale_code", "continent_code", "continent_name",
+		"country_iso_code", "country_name", "subdivision_1_iso_code",
+		"subdivision_1_name", "subdivision_2_iso_code", "subdivision_2_name",
+		"city_name", "metro_code", "time_zone", "is_in_european_union",
+	}
+	countryBlocksHeader = []string{
+		"network", "geoname_id", "registered_country_geoname_id",
+		"represented_country_geoname_id", "is_anonymous_proxy",
+		"is_satellite_provider", "is_anycast",
+	}
+	countryLocationsHeader = []string{
+		"geoname_id", "locale_code", "continent_code", "continent_name",
+		"country_iso_code", "country_name", "is_in_european_union",
+	}
+)
+
+// ExportCityCSV writes the networks in the database and their City records
+// to w in the layout of the GeoIP2 and GeoLite2 City CSV databases.
+//
+// The geoname_id of a network is that of its city or, if it has none, of its
+// country or continent. The locations are deduplicated by GeoNameID and
+// sorted by it. The is_anonymous_proxy and is_satellite_provider columns,
+// which are deprecated, are always 0, as the databases no longer contain
+// them.
+//
+// An InvalidMethodError is returned if the database does not support City
+// lookups. The writers are not closed.
+func (r *Reader) ExportCityCSV(w CSVWriters) error {
+	e := newCSVExport(w, cityBlocksHeader, cityLocationsHeader, true)
+	records := r.CityNetworks(maxminddb.SkipEmptyValues())
+	network := func(c *City) netip.Prefix { return c.Traits.Network }
+	return exportCSV(e, records, network, func(c *City) []string {
+		location := csvLocation{
+			continent: c.Continent,
+			country:   c.Country,
+			timeZone:  c.Location.TimeZone,
+		}
+		if c.City.GeoNameID != 0 {
+			location.city = c.City
+			location.subdivisions = c.Subdivisions
+			//nolint:staticcheck // The CSV databases still have a metro_code column.
+			location.metroCode = c.Location.MetroCode
+		}
+		row := e.blockRow(
+			c.Traits.Network,
+			location,
+			c.RegisteredCountry,
+			representedCountryRecord(c.RepresentedCountry),
+		)
+		latitude, longitude, accuracyRadius := "", "", ""
+		if c.Location.HasCoordinates() {
+			latitude = strconv.FormatFloat(*c.Location.Latitude, 'f', -1, 64)
+			longitude = strconv.FormatFloat(*c.Location.Longitude, 'f', -1, 64)
+			accuracyRadius = csvUint(uint(c.Location.AccuracyRadius))
+		}
+		return append(row,
+			c.Postal.Code,
+			latitude,
+			longitude,
+			accuracyRadius,
+			csvBool(c.Traits.IsAnycast),
+		)
+	})
+}
+
+// ExportCountryCSV writes the networks in the database and their Country
+// records to w in the layout of the GeoIP2 and GeoLite2 Country CSV
+// databases. It may also be used on City databases to export their country
+// data. See ExportCityCSV for details.
+func (r *Reader) ExportCountryCSV(w CSVWriters) error {
+	e := newCSVExport(w, countryBlocksHeader, countryLocationsHeader, false)
+	records := r.CountryNetworks(maxminddb.SkipEmptyValues())
+	network := func(c *Country) netip.Prefix { return c.Traits.Network }
+	return exportCSV(e, records, network, func(c *Country) []string {
+		row := e.blockRow(
+			c.Traits.Network,
+			csvLocation{continent: c.Continent, country: c.Country},
+			c.RegisteredCountry,
+			representedCountryRecord(c.RepresentedCountry),
+		)
+		return append(row, csvBool(c.Traits.IsAnycast))
+	})
+}
+
+func representedCountryRecord(c RepresentedCountry) CountryRecord {
+	return CountryRecord{
+		Names:             c.Names,
+		ISOCode:           c.ISOCode,
+		GeoNameID:         c.GeoNameID,
+		IsInEuropeanUnion: c.IsInEuropeanUnion,
+	}
+}
+
+// csvLocation is a row of the locations files, before localization.
+type csvLocation struct {
+	continent    Continent
+	country      CountryRecord
+	city         CityRecord
+	timeZone     string
+	subdivisions []CitySubdivision
+	metroCode    uint
+}
+
+// geoNameID returns the GeoNameID of the most specific place of l.
+func (l csvLocation) geoNameID() uint {
+	return cmp.Or(l.city.GeoNameID, l.country.GeoNameID, l.continent.GeoNameID)
+}
+
+type csvExport struct {
+	blocksIPv4 *csv.Writer
+	blocksIPv6 *csv.Writer
+	locations  map[string]*csv.Writer
+	seen       map[uint]*csvLocation
+	header     []string
+	city       bool
+}
+
+func newCSVExport(w CSVWriters, blocksHeader, locationsHeader []string, city bool) *csvExport {
+	e := &csvExport{
+		locations: map[string]*csv.Writer{},
+		seen:      map[uint]*csvLocation{},
+		header:    locationsHeader,
+		city:      city,
+	}
+	if w.BlocksIPv4 != nil {
+		e.blocksIPv4 = csv.NewWriter(w.BlocksIPv4)
+		_ = e.blocksIPv4.Write(blocksHeader)
+	}
+	if w.BlocksIPv6 != nil {
+		e.blocksIPv6 = csv.NewWriter(w.BlocksIPv6)
+		_ = e.blocksIPv6.Write(blocksHeader)
+	}
+	for locale, writer := range w.Locations {
+		e.locations[locale] = csv.NewWriter(writer)
+	}
+	return e
+}
+
+// addLocation records l, unless a location with the same GeoNameID was
+// already recorded. As the registered and represented countries of a network
+// have no continent, it is taken from later records of the same country.
+func (e *csvExport) addLocation(l csvLocation) uint {
+	id := l.geoNameID()
+	if id == 0 {
+		return 0
+	}
+	if seen, ok := e.seen[id]; ok {
+		if !seen.continent.HasData() {
+			seen.continent = l.continent
+		}
+		seen.timeZone = cmp.Or(seen.timeZone, l.timeZone)
+		return id
+	}
+	e.seen[id] = &l
+	return id
+}
+
+// blockRow returns the columns common to the City and Country blocks files.
+func (e *csvExport) blockRow(
+	network netip.Prefix,
+	location csvLocation,
+	registered, represented CountryRecord,
+) []string {
+	return []string{
+		network.String(),
+		csvUint(e.addLocation(location)),
+		csvUint(e.addLocation(csvLocation{country: registered})),
+		csvUint(e.addLocation(csvLocation{country: represented})),
+		"0",
+		"0",
+	}
+}
+
+// exportCSV writes the blocks files for records and then the locations files.
+// The locations are only those referenced by the networks written.
+func exportCSV[T any](
+	e *csvExport,
+	records iter.Seq2[*T, error],
+	network func(*T) netip.Prefix,
+	row func(*T) []string,
+) error {
+	for record, err := range records {
+		if err != nil {
+			return err
+		}
+		blocks := e.blocksIPv6
+		if network(record).Addr().Is4() {
+			blocks = e.blocksIPv4
+		}
+		if blocks == nil {
+			continue
+		}
+		if err := blocks.Write(row(record)); err != nil {
+			return err
+		}
+	}
+
+	for _, blocks := range []*csv.Writer{e.blocksIPv4, e.blocksIPv6} {
+		if blocks == nil {
+			continue
+		}
+		blocks.Flush()
+		if err := blocks.Error(); err != nil {
+			return err
+		}
+	}
+
+	ids := slices.Sorted(maps.Keys(e.seen))
+	for _, locale := range slices.Sorted(maps.Keys(e.locations)) {
+		writer := e.locations[locale]
+		if err := writer.Write(e.header); err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := writer.Write(e.locationRow(id, locale)); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// locationRow returns the row of the locations file for locale of the
+// location with the GeoNameID id.
+func (e *csvExport) locationRow(id uint, locale string) []string {
+	l := e.seen[id]
+	row := []string{
+		csvUint(id),
+		locale,
+		l.continent.Code,
+		l.continent.Names.Get(locale),
+		l.country.ISOCode,
+		l.country.Names.Get(locale),
+	}
+	if e.city {
+		var subdivisions [2]CitySubdivision
+		copy(subdivisions[:], l.subdivisions)
+		row = append(row,
+			subdivisions[0].ISOCode,
+			subdivisions[0].Names.Get(locale),
+			subdivisions[1].ISOCode,
+			subdivisions[1].Names.Get(locale),
+			l.city.Names.Get(locale),
+			csvUint(l.metroCode),
+			l.timeZone,
+		)
+	}
+	return append(row, csvBool(l.country.IsInEuropeanUnion))
+}
+
+func csvUint(n uint) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(n), 10)
+}
+
+func csvBool(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,170 @@
+package geoip2
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"net/netip"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readCSV returns the records of data, keyed by column name.
+func readCSV(t *testing.T, data []byte, header []string) []map[string]string {
+	t.Helper()
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	require.NoError(t, err)
+	require.NotEmpty(t, records)
+	require.Equal(t, header, records[0])
+
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := map[string]string{}
+		for i, column := range header {
+			row[column] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// findBlock returns the row of blocks whose network contains ip.
+func findBlock(t *testing.T, blocks []map[string]string, ip string) map[string]string {
+	t.Helper()
+	addr := netip.MustParseAddr(ip)
+	for _, row := range blocks {
+		if netip.MustParsePrefix(row["network"]).Contains(addr) {
+			return row
+		}
+	}
+	require.Failf(t, "block not found", "no network contains %s", ip)
+	return nil
+}
+
+// checkLocations checks that the locations are sorted and unique, and that
+// every GeoNameID referenced by blocks has one. It returns the locations by
+// GeoNameID.
+func checkLocations(
+	t *testing.T,
+	locations []map[string]string,
+	blocks ...[]map[string]string,
+) map[string]map[string]string {
+	t.Helper()
+	byID := map[string]map[string]string{}
+	var ids []int
+	for _, row := range locations {
+		id, err := strconv.Atoi(row["geoname_id"])
+		require.NoError(t, err)
+		ids = append(ids, id)
+		byID[row["geoname_id"]] = row
+	}
+	assert.True(t, slices.IsSorted(ids))
+	assert.Len(t, byID, len(locations))
+
+	for _, rows := range blocks {
+		for _, row := range rows {
+			for _, column := range []string{
+				"geoname_id",
+				"registered_country_geoname_id",
+				"represented_country_geoname_id",
+			} {
+				if id := row[column]; id != "" {
+					assert.Contains(t, byID, id, "%s of %s", column, row["network"])
+				}
+			}
+		}
+	}
+	return byID
+}
+
+func TestExportCityCSV(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	var ipv4, ipv6, en, de bytes.Buffer
+	require.NoError(t, reader.ExportCityCSV(CSVWriters{
+		BlocksIPv4: &ipv4,
+		BlocksIPv6: &ipv6,
+		Locations:  map[string]io.Writer{"en": &en, "de": &de},
+	}))
+
+	blocks4 := readCSV(t, ipv4.Bytes(), cityBlocksHeader)
+	blocks6 := readCSV(t, ipv6.Bytes(), cityBlocksHeader)
+	for _, row := range blocks4 {
+		assert.True(t, netip.MustParsePrefix(row["network"]).Addr().Is4(), row["network"])
+	}
+	for _, row := range blocks6 {
+		assert.True(t, netip.MustParsePrefix(row["network"]).Addr().Is6(), row["network"])
+	}
+
+	london := findBlock(t, blocks4, "81.2.69.160")
+	assert.Equal(t, "2643743", london["geoname_id"])
+	assert.Equal(t, "51.5142", london["latitude"])
+	assert.Equal(t, "-0.0931", london["longitude"])
+	assert.Equal(t, "0", london["is_anonymous_proxy"])
+
+	locations := checkLocations(t, readCSV(t, en.Bytes(), cityLocationsHeader), blocks4, blocks6)
+	assert.Equal(t, map[string]string{
+		"geoname_id":             "2643743",
+		"locale_code":            "en",
+		"continent_code":         "EU",
+		"continent_name":         "Europe",
+		"country_iso_code":       "GB",
+		"country_name":           "United Kingdom",
+		"subdivision_1_iso_code": "ENG",
+		"subdivision_1_name":     "England",
+		"subdivision_2_iso_code": "",
+		"subdivision_2_name":     "",
+		"city_name":              "London",
+		"metro_code":             "",
+		"time_zone":              "Europe/London",
+		"is_in_european_union":   "0",
+	}, locations["2643743"])
+
+	locations = checkLocations(t, readCSV(t, de.Bytes(), cityLocationsHeader), blocks4, blocks6)
+	assert.Equal(t, "de", locations["2643743"]["locale_code"])
+	assert.Equal(t, "Vereinigtes Königreich", locations["2643743"]["country_name"])
+}
+
+func TestExportCountryCSV(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-Country-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	var ipv4, en bytes.Buffer
+	require.NoError(t, reader.ExportCountryCSV(CSVWriters{
+		BlocksIPv4: &ipv4,
+		Locations:  map[string]io.Writer{"en": &en},
+	}))
+
+	blocks := readCSV(t, ipv4.Bytes(), countryBlocksHeader)
+	assert.Equal(t, "2635167", findBlock(t, blocks, "81.2.69.160")["geoname_id"])
+
+	locations := checkLocations(t, readCSV(t, en.Bytes(), countryLocationsHeader), blocks)
+	assert.Equal(t, map[string]string{
+		"geoname_id":           "2635167",
+		"locale_code":          "en",
+		"continent_code":       "EU",
+		"continent_name":       "Europe",
+		"country_iso_code":     "GB",
+		"country_name":         "United Kingdom",
+		"is_in_european_union": "0",
+	}, locations["2635167"])
+}
+
+func TestExportCSVInvalidMethod(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoLite2-ASN-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	var methodErr InvalidMethodError
+	err = reader.ExportCityCSV(CSVWriters{BlocksIPv4: io.Discard})
+	require.ErrorAs(t, err, &methodErr)
+	err = reader.ExportCountryCSV(CSVWriters{BlocksIPv4: io.Discard})
+	require.ErrorAs(t, err, &methodErr)
+}
@@ -0,0 +1,94 @@
+package geoip2
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var (
+	customDatabaseTypes   = map[string]databaseType{}
+	customDatabaseTypesMu sync.RWMutex
+)
+
+// compatPrefixes are tried, in order, when resolving the database type named
+// in a "(compat=...)" suffix, so that both "City" and "GeoIP2-City" may be
+// used.
+var compatPrefixes = []string{"", "GeoIP2-", "GeoLite2-", "GeoIP-"}
+
+// RegisterDatabaseType registers name as a database type supporting the same
+// lookup methods as the database type base. This allows opening databases
+// with a custom DatabaseType in their metadata, e.g.:
+//
+//	err := geoip2.RegisterDatabaseType("Acme-City", "GeoIP2-City")
+//
+// base may be any database type supported by this package, an abbreviated
+// name such as "City", "ASN", or "ISP", or a previously registered type.
+// Registering name again replaces the previous registration. An error is
+// returned if name is a built-in database type or if base is unknown.
+//
+// Databases whose type has a "(compat=...)" suffix, e.g.,
+// "DBIP-Location (compat=City)", do not need to be registered, as the suffix
+// is used to determine the supported lookup methods.
+func RegisterDatabaseType(name, base string) error {
+	if name == "" {
+		return errors.New("geoip2: the database type name must not be empty")
+	}
+	if _, ok := builtinDatabaseType(name); ok {
+		return fmt.Errorf("geoip2: cannot register the built-in %q database type", name)
+	}
+	dbType, ok := resolveCompatType(base)
+	if !ok {
+		return UnknownDatabaseTypeError{base}
+	}
+
+	customDatabaseTypesMu.Lock()
+	defer customDatabaseTypesMu.Unlock()
+	customDatabaseTypes[name] = dbType
+	return nil
+}
+
+// resolveDatabaseType returns the lookup methods supported by the database
+// type name.
+func resolveDatabaseType(name string) (databaseType, bool) {
+	if dbType, ok := builtinDatabaseType(name); ok {
+		return dbType, true
+	}
+
+	customDatabaseTypesMu.RLock()
+	dbType, ok := customDatabaseTypes[name]
+	customDatabaseTypesMu.RUnlock()
+	if ok {
+		return dbType, true
+	}
+
+	if compat, ok := parseCompat(name); ok {
+		return resolveCompatType(compat)
+	}
+	return 0, false
+}
+
+// resolveCompatType resolves name, which may omit the product prefix, e.g.,
+// "City" for "GeoIP2-City".
+func resolveCompatType(name string) (databaseType, bool) {
+	for _, prefix := range compatPrefixes {
+		if dbType, ok := resolveDatabaseType(prefix + name); ok {
+			return dbType, true
+		}
+	}
+	return 0, false
+}
+
+// parseCompat returns X from a database type of the form "Name (compat=X)".
+func parseCompat(name string) (string, bool) {
+	_, compat, ok := strings.Cut(name, " (compat=")
+	if !ok {
+		return "", false
+	}
+	compat, ok = strings.CutSuffix(compat, ")")
+	if !ok || compat == "" || strings.Contains(compat, "(compat=") {
+		return "", false
+	}
+	return compat, true
+}
@@ -0,0 +1,75 @@
+package geoip2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveDatabaseTypeCompat(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected databaseType
+	}{
+		{"DBIP-ASN-Lite (compat=GeoLite2-ASN)", isASN},
+		{"DBIP-Location (compat=City)", isCity | isCountry},
+		{"DBIP-ISP (compat=Enterprise)", isEnterprise | isCity | isCountry},
+		{"DBIP-Location-ISP (compat=Enterprise)", isEnterprise | isCity | isCountry},
+		{"Example-Proxy (compat=Anonymous-IP)", isAnonymousIP},
+		{"Example-Carrier (compat=GeoIP2-ISP)", isISP | isASN},
+		{"Example-Country (compat=Country)", isCity | isCountry},
+	}
+	for _, test := range tests {
+		dbType, ok := resolveDatabaseType(test.name)
+		require.True(t, ok, test.name)
+		assert.Equal(t, test.expected, dbType, test.name)
+	}
+
+	for _, name := range []string{
+		"Example-City",
+		"Example (compat=Unknown)",
+		"Example (compat=)",
+		"Example (compat=City",
+		"Example (compat=Foo (compat=City))",
+	} {
+		_, ok := resolveDatabaseType(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestRegisterDatabaseType(t *testing.T) {
+	t.Cleanup(func() {
+		customDatabaseTypesMu.Lock()
+		defer customDatabaseTypesMu.Unlock()
+		for _, name := range []string{"Acme-City", "Acme-ISP", "Acme-City-Lite"} {
+			delete(customDatabaseTypes, name)
+		}
+	})
+
+	require.NoError(t, RegisterDatabaseType("Acme-City", "GeoIP2-City"))
+	dbType, ok := resolveDatabaseType("Acme-City")
+	require.True(t, ok)
+	assert.Equal(t, databaseType(isCity|isCountry), dbType)
+
+	require.NoError(t, RegisterDatabaseType("Acme-ISP", "ISP"))
+	dbType, ok = resolveDatabaseType("Acme-ISP")
+	require.True(t, ok)
+	assert.Equal(t, databaseType(isISP|isASN), dbType)
+
+	// Registered types may be used as the base of other types, as well as in
+	// compat suffixes.
+	require.NoError(t, RegisterDatabaseType("Acme-City-Lite", "Acme-City"))
+	dbType, ok = resolveDatabaseType("Acme-City-Lite")
+	require.True(t, ok)
+	assert.Equal(t, databaseType(isCity|isCountry), dbType)
+
+	dbType, ok = resolveDatabaseType("Other-ISP (compat=Acme-ISP)")
+	require.True(t, ok)
+	assert.Equal(t, databaseType(isISP|isASN), dbType)
+
+	require.Error(t, RegisterDatabaseType("GeoIP2-City", "GeoLite2-ASN"))
+	require.Error(t, RegisterDatabaseType("", "GeoIP2-City"))
+	err := RegisterDatabaseType("Acme-Other", "Unknown")
+	require.ErrorAs(t, err, &UnknownDatabaseTypeError{})
+}
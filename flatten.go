@@ -0,0 +1,49 @@
+package geoip2
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// FlattenRecord returns the fields of the JSON encoding of record, e.g., a
+// *City, keyed by their path, such as "country.iso_code" or
+// "subdivisions.0.names.en". Numbers are returned as json.Number, so that
+// integers such as GeoNameIDs remain exact. Fields omitted from the JSON
+// encoding, such as those without data, are not included.
+func FlattenRecord(record any) (map[string]any, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	fields := map[string]any{}
+	flatten(fields, "", value)
+	return fields, nil
+}
+
+func flatten(fields map[string]any, path string, value any) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+	switch value := value.(type) {
+	case map[string]any:
+		for key, v := range value {
+			flatten(fields, join(key), v)
+		}
+	case []any:
+		for i, v := range value {
+			flatten(fields, join(strconv.Itoa(i)), v)
+		}
+	default:
+		fields[path] = value
+	}
+}
@@ -0,0 +1,38 @@
+package geoip2
+
+import (
+	"encoding/json"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlattenRecord(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoLite2-ASN-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	record, err := reader.ASN(netip.MustParseAddr("1.128.0.0"))
+	require.NoError(t, err)
+
+	fields, err := FlattenRecord(record)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"autonomous_system_number":       json.Number("1221"),
+		"autonomous_system_organization": "Telstra Pty Ltd",
+		"ip_address":                     "1.128.0.0",
+		"network":                        "1.128.0.0/11",
+	}, fields)
+
+	fields, err = FlattenRecord(map[string]any{
+		"a": []any{map[string]any{"b": true}, "c"},
+		"d": map[string]any{},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a.0.b": true, "a.1": "c"}, fields)
+
+	_, err = FlattenRecord(make(chan int))
+	require.Error(t, err)
+}
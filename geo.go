@@ -0,0 +1,196 @@
+package geoip2
+
+import (
+	"math"
+	"time"
+)
+
+// The parameters of the WGS-84 ellipsoid, used by Distance.
+const (
+	wgs84SemiMajorAxis = 6378.137 // km
+	wgs84Flattening    = 1 / 298.257223563
+	wgs84SemiMinorAxis = wgs84SemiMajorAxis * (1 - wgs84Flattening)
+
+	// meanEarthRadius is the mean radius of the Earth, used by
+	// HaversineDistance.
+	meanEarthRadius = 6371.0088 // km
+)
+
+// DefaultMaxTravelSpeed is a maximum travel speed, in kilometers per hour,
+// suitable for AssessTravel. It is somewhat above the cruising speed of a
+// commercial airliner.
+const DefaultMaxTravelSpeed = 1000.0
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// HaversineDistance returns the great-circle distance in kilometers between
+// two points, given as latitude and longitude in degrees, on a sphere with
+// the mean radius of the Earth. It is faster than Distance, but its error may
+// reach about 0.5%.
+func HaversineDistance(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1, phi2 := radians(lat1), radians(lat2)
+	dPhi := phi2 - phi1
+	dLambda := radians(lon2 - lon1)
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * meanEarthRadius * math.Asin(min(1, math.Sqrt(a)))
+}
+
+// Distance returns the geodesic distance in kilometers between two points,
+// given as latitude and longitude in degrees, on the WGS-84 ellipsoid, using
+// Vincenty's inverse formula. For nearly antipodal points, for which the
+// formula does not converge, the result of HaversineDistance is returned.
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	const (
+		a = wgs84SemiMajorAxis
+		b = wgs84SemiMinorAxis
+		f = wgs84Flattening
+	)
+
+	l := radians(lon2 - lon1)
+	u1 := math.Atan((1 - f) * math.Tan(radians(lat1)))
+	u2 := math.Atan((1 - f) * math.Tan(radians(lat2)))
+	sinU1, cosU1 := math.Sincos(u1)
+	sinU2, cosU2 := math.Sincos(u2)
+
+	lambda := l
+	for range 200 {
+		sinLambda, cosLambda := math.Sincos(lambda)
+		sinSigma := math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			// The points coincide.
+			return 0
+		}
+		cosSigma := sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma := math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cosSqAlpha := 1 - sinAlpha*sinAlpha
+		cos2SigmaM := 0.0
+		if cosSqAlpha != 0 {
+			// cosSqAlpha is zero for points on the equator.
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cosSqAlpha
+		}
+		c := f / 16 * cosSqAlpha * (4 + f*(4-3*cosSqAlpha))
+		previous := lambda
+		lambda = l + (1-c)*f*sinAlpha*
+			(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-previous) > 1e-12 {
+			continue
+		}
+
+		uSq := cosSqAlpha * (a*a - b*b) / (b * b)
+		bigA := 1 + uSq/16384*(4096+uSq*(-768+uSq*(320-175*uSq)))
+		bigB := uSq / 1024 * (256 + uSq*(-128+uSq*(74-47*uSq)))
+		deltaSigma := bigB * sinSigma * (cos2SigmaM + bigB/4*
+			(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+				bigB/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+		return b * bigA * (sigma - deltaSigma)
+	}
+	return HaversineDistance(lat1, lon1, lat2, lon2)
+}
+
+// Bearing returns the initial bearing in degrees, clockwise from north and in
+// the range [0, 360), of the great-circle path from the first point to the
+// second, given as latitude and longitude in degrees.
+func Bearing(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1, phi2 := radians(lat1), radians(lat2)
+	dLambda := radians(lon2 - lon1)
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	bearing := math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+	if bearing == 360 {
+		// math.Mod may round tiny negative angles up to 360.
+		return 0
+	}
+	return bearing
+}
+
+// DistanceTo returns the distance in kilometers between the coordinates of
+// the Location and those of other, as computed by Distance. It returns false
+// if either Location lacks coordinates.
+func (l Location) DistanceTo(other Location) (float64, bool) {
+	if !l.HasCoordinates() || !other.HasCoordinates() {
+		return 0, false
+	}
+	return Distance(*l.Latitude, *l.Longitude, *other.Latitude, *other.Longitude), true
+}
+
+// DistanceToPoint returns the distance in kilometers between the coordinates
+// of the Location and the point given as latitude and longitude in degrees,
+// as computed by Distance. It returns false if the Location lacks
+// coordinates.
+func (l Location) DistanceToPoint(latitude, longitude float64) (float64, bool) {
+	if !l.HasCoordinates() {
+		return 0, false
+	}
+	return Distance(*l.Latitude, *l.Longitude, latitude, longitude), true
+}
+
+// BearingTo returns the initial bearing in degrees from the coordinates of
+// the Location to those of other, as computed by Bearing. It returns false if
+// either Location lacks coordinates.
+func (l Location) BearingTo(other Location) (float64, bool) {
+	if !l.HasCoordinates() || !other.HasCoordinates() {
+		return 0, false
+	}
+	return Bearing(*l.Latitude, *l.Longitude, *other.Latitude, *other.Longitude), true
+}
+
+// BearingToPoint returns the initial bearing in degrees from the coordinates
+// of the Location to the point given as latitude and longitude in degrees, as
+// computed by Bearing. It returns false if the Location lacks coordinates.
+func (l Location) BearingToPoint(latitude, longitude float64) (float64, bool) {
+	if !l.HasCoordinates() {
+		return 0, false
+	}
+	return Bearing(*l.Latitude, *l.Longitude, latitude, longitude), true
+}
+
+// Travel is the result of AssessTravel.
+type Travel struct {
+	// Distance is the distance in kilometers between the coordinates of the
+	// two locations.
+	Distance float64
+	// MinDistance is the smallest distance in kilometers that may have been
+	// traveled, taking into account the accuracy radius of both locations.
+	MinDistance float64
+	// RequiredSpeed is the speed in kilometers per hour needed to travel
+	// MinDistance in the elapsed time. It is infinite if MinDistance is not
+	// zero and no time has elapsed.
+	RequiredSpeed float64
+	// Impossible is true if RequiredSpeed exceeds the maximum speed.
+	Impossible bool
+}
+
+// AssessTravel determines whether someone could plausibly have moved from
+// the location from to the location to, e.g., those of two consecutive
+// logins, in the elapsed time without exceeding maxSpeed, in kilometers per
+// hour. See DefaultMaxTravelSpeed.
+//
+// As the coordinates of a location are only approximate, the actual
+// locations may be anywhere within their accuracy radius. Travel is therefore
+// only considered impossible if even the shortest distance between these
+// circles cannot be covered in time. AssessTravel returns false if either
+// location lacks coordinates, in which case no assessment can be made.
+func AssessTravel(from, to Location, elapsed time.Duration, maxSpeed float64) (Travel, bool) {
+	distance, ok := from.DistanceTo(to)
+	if !ok {
+		return Travel{}, false
+	}
+	travel := Travel{
+		Distance:    distance,
+		MinDistance: max(0, distance-float64(from.AccuracyRadius)-float64(to.AccuracyRadius)),
+	}
+	switch {
+	case travel.MinDistance == 0:
+		travel.RequiredSpeed = 0
+	case elapsed <= 0:
+		travel.RequiredSpeed = math.Inf(1)
+	default:
+		travel.RequiredSpeed = travel.MinDistance / elapsed.Hours()
+	}
+	travel.Impossible = travel.RequiredSpeed > maxSpeed
+	return travel, true
+}
@@ -0,0 +1,109 @@
+package geoip2
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func location(latitude, longitude float64, accuracyRadius uint16) Location {
+	return Location{Latitude: &latitude, Longitude: &longitude, AccuracyRadius: accuracyRadius}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		expected               float64
+	}{
+		// Flinders Peak to Buninyong, the example of Vincenty's paper.
+		{"Flinders Peak", -37.95103341666667, 144.42486788888888, -37.65282113888889, 143.92649552777777, 54.972271},
+		// London to New York.
+		{"London", 51.5074, -0.1278, 40.7128, -74.0060, 5585.2},
+		{"equator", 0, 0, 0, 90, 10018.754},
+		{"same point", 51.5, -0.1, 51.5, -0.1, 0},
+	}
+	for _, test := range tests {
+		assert.InDelta(t, test.expected, Distance(test.lat1, test.lon1, test.lat2, test.lon2),
+			0.1, test.name)
+	}
+
+	// Nearly antipodal points fall back to the haversine formula.
+	d := Distance(0, 0, 0.5, 179.7)
+	assert.InDelta(t, HaversineDistance(0, 0, 0.5, 179.7), d, 100)
+
+	assert.InDelta(t, 5570.2, HaversineDistance(51.5074, -0.1278, 40.7128, -74.0060), 1)
+}
+
+func TestBearing(t *testing.T) {
+	assert.InDelta(t, 0, Bearing(0, 0, 10, 0), 1e-9)
+	assert.InDelta(t, 90, Bearing(0, 0, 0, 10), 1e-9)
+	assert.InDelta(t, 180, Bearing(10, 0, 0, 0), 1e-9)
+	assert.InDelta(t, 270, Bearing(0, 10, 0, 0), 1e-9)
+	// London to New York.
+	assert.InDelta(t, 288.3, Bearing(51.5074, -0.1278, 40.7128, -74.0060), 0.1)
+}
+
+func TestLocationDistanceTo(t *testing.T) {
+	london := location(51.5074, -0.1278, 10)
+	newYork := location(40.7128, -74.0060, 10)
+
+	d, ok := london.DistanceTo(newYork)
+	require.True(t, ok)
+	assert.InDelta(t, 5585.2, d, 0.1)
+
+	d, ok = london.DistanceToPoint(40.7128, -74.0060)
+	require.True(t, ok)
+	assert.InDelta(t, 5585.2, d, 0.1)
+
+	b, ok := london.BearingTo(newYork)
+	require.True(t, ok)
+	assert.InDelta(t, 288.3, b, 0.1)
+
+	b, ok = london.BearingToPoint(40.7128, -74.0060)
+	require.True(t, ok)
+	assert.InDelta(t, 288.3, b, 0.1)
+
+	_, ok = london.DistanceTo(Location{})
+	assert.False(t, ok)
+	_, ok = Location{}.DistanceToPoint(0, 0)
+	assert.False(t, ok)
+	_, ok = Location{}.BearingTo(london)
+	assert.False(t, ok)
+	_, ok = Location{}.BearingToPoint(0, 0)
+	assert.False(t, ok)
+}
+
+func TestAssessTravel(t *testing.T) {
+	london := location(51.5074, -0.1278, 50)
+	newYork := location(40.7128, -74.0060, 50)
+
+	travel, ok := AssessTravel(london, newYork, time.Hour, DefaultMaxTravelSpeed)
+	require.True(t, ok)
+	assert.InDelta(t, 5585.2, travel.Distance, 0.1)
+	assert.InDelta(t, 5485.2, travel.MinDistance, 0.1)
+	assert.InDelta(t, 5485.2, travel.RequiredSpeed, 0.1)
+	assert.True(t, travel.Impossible)
+
+	travel, ok = AssessTravel(london, newYork, 8*time.Hour, DefaultMaxTravelSpeed)
+	require.True(t, ok)
+	assert.False(t, travel.Impossible)
+
+	// Overlapping accuracy radii are always plausible.
+	nearby := location(51.7, -0.1278, 50)
+	travel, ok = AssessTravel(london, nearby, 0, DefaultMaxTravelSpeed)
+	require.True(t, ok)
+	assert.Zero(t, travel.MinDistance)
+	assert.False(t, travel.Impossible)
+
+	travel, ok = AssessTravel(london, newYork, 0, DefaultMaxTravelSpeed)
+	require.True(t, ok)
+	assert.True(t, math.IsInf(travel.RequiredSpeed, 1))
+	assert.True(t, travel.Impossible)
+
+	_, ok = AssessTravel(london, Location{}, time.Hour, DefaultMaxTravelSpeed)
+	assert.False(t, ok)
+}
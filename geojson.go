@@ -0,0 +1,277 @@
+package geoip2
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"math"
+	"net/netip"
+
+	"github.com/oschwald/maxminddb-golang/v2"
+)
+
+// GeoJSONGeometry is a GeoJSON geometry object, as specified by RFC 7946.
+type GeoJSONGeometry struct {
+	// Type is the type of the geometry, e.g., "Point".
+	Type string `json:"type"`
+	// Coordinates are the coordinates of the geometry, as longitude and
+	// latitude. They are unset for a "GeometryCollection".
+	Coordinates any `json:"coordinates,omitempty"`
+	// Geometries are the geometries of a "GeometryCollection".
+	Geometries []GeoJSONGeometry `json:"geometries,omitempty"`
+}
+
+// GeoJSONFeature is a GeoJSON Feature object, as specified by RFC 7946.
+type GeoJSONFeature struct {
+	// Type is always "Feature".
+	Type string `json:"type"`
+	// Geometry is the geometry of the feature. It is nil if the location of
+	// the record has no coordinates.
+	Geometry *GeoJSONGeometry `json:"geometry"`
+	// Properties are the fields of the record, flattened into keys such as
+	// "country.iso_code" and "subdivisions.0.names.en".
+	Properties map[string]any `json:"properties"`
+}
+
+type geoJSONOptions struct {
+	circleSegments int
+}
+
+// GeoJSONOption configures the GeoJSON encoding of records.
+type GeoJSONOption func(*geoJSONOptions)
+
+// CircleSegments sets the number of segments of the polygon approximating the
+// accuracy radius of a location. The default is 64. If n is less than 3, no
+// polygon is included and the geometry is only a point.
+func CircleSegments(n int) GeoJSONOption {
+	return func(o *geoJSONOptions) {
+		o.circleSegments = n
+	}
+}
+
+func newGeoJSONOptions(options []GeoJSONOption) geoJSONOptions {
+	opts := geoJSONOptions{circleSegments: 64}
+	for _, option := range options {
+		option(&opts)
+	}
+	return opts
+}
+
+// geoJSONPrecision is the number of decimal places of coordinates, about 10
+// centimeters, as recommended by RFC 7946.
+const geoJSONPrecision = 1e6
+
+func geoJSONPosition(latitude, longitude float64) []float64 {
+	return []float64{
+		math.Round(longitude*geoJSONPrecision) / geoJSONPrecision,
+		math.Round(latitude*geoJSONPrecision) / geoJSONPrecision,
+	}
+}
+
+// GeoJSONGeometry returns the geometry of the Location: a "Point" at its
+// coordinates or, if it has an accuracy radius, a "GeometryCollection" of the
+// point and a "Polygon" approximating the circle of the accuracy radius. It
+// returns nil if the Location has no coordinates.
+//
+// The polygon is not split at the antimeridian, so its longitudes may exceed
+// 180 degrees in absolute value.
+func (l Location) GeoJSONGeometry(options ...GeoJSONOption) *GeoJSONGeometry {
+	if !l.HasCoordinates() {
+		return nil
+	}
+	opts := newGeoJSONOptions(options)
+	point := GeoJSONGeometry{
+		Type:        "Point",
+		Coordinates: geoJSONPosition(*l.Latitude, *l.Longitude),
+	}
+	if l.AccuracyRadius == 0 || opts.circleSegments < 3 {
+		return &point
+	}
+	circle := GeoJSONGeometry{
+		Type: "Polygon",
+		Coordinates: [][][]float64{
+			accuracyCircle(*l.Latitude, *l.Longitude, float64(l.AccuracyRadius), opts.circleSegments),
+		},
+	}
+	return &GeoJSONGeometry{
+		Type:       "GeometryCollection",
+		Geometries: []GeoJSONGeometry{point, circle},
+	}
+}
+
+// accuracyCircle returns the closed linear ring approximating the circle of
+// radius kilometers around a point, with the given number of segments. The
+// ring is counterclockwise, as RFC 7946 requires of exterior rings.
+func accuracyCircle(latitude, longitude, radius float64, segments int) [][]float64 {
+	phi1, lambda1 := radians(latitude), radians(longitude)
+	sinPhi1, cosPhi1 := math.Sincos(phi1)
+	sinDelta, cosDelta := math.Sincos(radius / meanEarthRadius)
+
+	ring := make([][]float64, 0, segments+1)
+	for i := range segments {
+		// Bearings are clockwise, so they are traversed backward.
+		theta := 2 * math.Pi * float64(segments-i) / float64(segments)
+		sinTheta, cosTheta := math.Sincos(theta)
+		sinPhi2 := sinPhi1*cosDelta + cosPhi1*sinDelta*cosTheta
+		phi2 := math.Asin(sinPhi2)
+		lambda2 := lambda1 + math.Atan2(sinTheta*sinDelta*cosPhi1, cosDelta-sinPhi1*sinPhi2)
+		ring = append(ring, geoJSONPosition(phi2*180/math.Pi, lambda2*180/math.Pi))
+	}
+	return append(ring, ring[0])
+}
+
+// GeoJSONFeature returns the City record as a GeoJSON feature. See
+// Location.GeoJSONGeometry for its geometry and GeoJSONFeature for its
+// properties.
+func (c City) GeoJSONFeature(options ...GeoJSONOption) (GeoJSONFeature, error) {
+	return newGeoJSONFeature(c, c.Location, options)
+}
+
+// GeoJSONFeature returns the Enterprise record as a GeoJSON feature. See
+// Location.GeoJSONGeometry for its geometry and GeoJSONFeature for its
+// properties.
+func (e Enterprise) GeoJSONFeature(options ...GeoJSONOption) (GeoJSONFeature, error) {
+	return newGeoJSONFeature(e, e.Location, options)
+}
+
+func newGeoJSONFeature(record any, location Location, options []GeoJSONOption) (GeoJSONFeature, error) {
+	properties, err := FlattenRecord(record)
+	if err != nil {
+		return GeoJSONFeature{}, err
+	}
+	return GeoJSONFeature{
+		Type:       "Feature",
+		Geometry:   location.GeoJSONGeometry(options...),
+		Properties: properties,
+	}, nil
+}
+
+// GeoJSONWriter writes a GeoJSON FeatureCollection one feature at a time, so
+// that collections of any size may be written without holding them in
+// memory.
+type GeoJSONWriter struct {
+	w       io.Writer
+	err     error
+	started bool
+	closed  bool
+}
+
+// NewGeoJSONWriter returns a GeoJSONWriter writing to w.
+func NewGeoJSONWriter(w io.Writer) *GeoJSONWriter {
+	return &GeoJSONWriter{w: w}
+}
+
+var errGeoJSONWriterClosed = errors.New("geoip2: the GeoJSON writer is closed")
+
+// Write writes feature to the collection.
+func (w *GeoJSONWriter) Write(feature GeoJSONFeature) error {
+	if w.closed {
+		return errGeoJSONWriterClosed
+	}
+	if w.err != nil {
+		return w.err
+	}
+	data, err := json.Marshal(feature)
+	if err != nil {
+		return err
+	}
+	separator := ",\n"
+	if !w.started {
+		separator = `{"type":"FeatureCollection","features":[` + "\n"
+		w.started = true
+	}
+	if _, err := io.WriteString(w.w, separator); err != nil {
+		w.err = err
+		return err
+	}
+	if _, err := w.w.Write(data); err != nil {
+		w.err = err
+		return err
+	}
+	return nil
+}
+
+// Close ends the collection. It does not close the underlying writer.
+func (w *GeoJSONWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.err != nil {
+		return w.err
+	}
+	end := "\n]}\n"
+	if !w.started {
+		end = `{"type":"FeatureCollection","features":[]}` + "\n"
+	}
+	_, err := io.WriteString(w.w, end)
+	return err
+}
+
+// WriteGeoJSON writes the networks in the database to w as a GeoJSON
+// FeatureCollection, with one feature per network that has coordinates. The
+// features are the Enterprise records of an Enterprise database and the City
+// records of a City database. Other databases return an InvalidMethodError.
+//
+// The options are passed on to the GeoJSONFeature method of the records.
+func (r *Reader) WriteGeoJSON(w io.Writer, options ...GeoJSONOption) error {
+	return r.writeGeoJSON(w, options, nil)
+}
+
+// WriteGeoJSONWithin writes the networks in the database that are contained
+// within prefix to w as a GeoJSON FeatureCollection, as WriteGeoJSON does.
+func (r *Reader) WriteGeoJSONWithin(
+	w io.Writer,
+	prefix netip.Prefix,
+	options ...GeoJSONOption,
+) error {
+	return r.writeGeoJSON(w, options, &prefix)
+}
+
+func (r *Reader) writeGeoJSON(w io.Writer, options []GeoJSONOption, prefix *netip.Prefix) error {
+	switch {
+	case r.databaseType&isEnterprise != 0:
+		return writeGeoJSONNetworks(w, geoJSONNetworks[Enterprise](r, MethodEnterprise, prefix),
+			func(e *Enterprise) (GeoJSONFeature, error) { return e.GeoJSONFeature(options...) })
+	case r.databaseType&isCity != 0:
+		return writeGeoJSONNetworks(w, geoJSONNetworks[City](r, MethodCity, prefix),
+			func(c *City) (GeoJSONFeature, error) { return c.GeoJSONFeature(options...) })
+	default:
+		return InvalidMethodError{"WriteGeoJSON", r.Metadata().DatabaseType}
+	}
+}
+
+// geoJSONNetworks returns an iterator over the networks in the database, or
+// over those within prefix if it is not nil.
+func geoJSONNetworks[T any](r *Reader, method Method, prefix *netip.Prefix) iter.Seq2[*T, error] {
+	if prefix != nil {
+		return NetworksWithin[T](r, *prefix, method, maxminddb.SkipEmptyValues())
+	}
+	return Networks[T](r, method, maxminddb.SkipEmptyValues())
+}
+
+func writeGeoJSONNetworks[T any](
+	w io.Writer,
+	records iter.Seq2[*T, error],
+	encode func(*T) (GeoJSONFeature, error),
+) error {
+	writer := NewGeoJSONWriter(w)
+	for record, err := range records {
+		if err != nil {
+			return err
+		}
+		feature, err := encode(record)
+		if err != nil {
+			return err
+		}
+		if feature.Geometry == nil {
+			// Features without coordinates cannot be drawn.
+			continue
+		}
+		if err := writer.Write(feature); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
@@ -0,0 +1,153 @@
+package geoip2
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocationGeoJSONGeometry(t *testing.T) {
+	assert.Nil(t, Location{}.GeoJSONGeometry())
+
+	point := location(51.5142, -0.0931, 0).GeoJSONGeometry()
+	require.NotNil(t, point)
+	assert.Equal(t, "Point", point.Type)
+	assert.Equal(t, []float64{-0.0931, 51.5142}, point.Coordinates)
+
+	geometry := location(51.5142, -0.0931, 100).GeoJSONGeometry(CircleSegments(8))
+	require.NotNil(t, geometry)
+	assert.Equal(t, "GeometryCollection", geometry.Type)
+	require.Len(t, geometry.Geometries, 2)
+	assert.Equal(t, *point, geometry.Geometries[0])
+
+	circle := geometry.Geometries[1]
+	assert.Equal(t, "Polygon", circle.Type)
+	rings := circle.Coordinates.([][][]float64)
+	require.Len(t, rings, 1)
+	ring := rings[0]
+	require.Len(t, ring, 9)
+	assert.Equal(t, ring[0], ring[8])
+
+	// The ring starts due north and is counterclockwise, so its second
+	// position is to the northwest.
+	assert.InDelta(t, -0.0931, ring[0][0], 1e-6)
+	assert.Less(t, ring[1][0], -0.0931)
+	assert.Greater(t, ring[1][1], 51.5142)
+	for _, position := range ring {
+		d := Distance(51.5142, -0.0931, position[1], position[0])
+		assert.InDelta(t, 100, d, 1)
+	}
+
+	geometry = location(51.5142, -0.0931, 100).GeoJSONGeometry(CircleSegments(0))
+	assert.Equal(t, point, geometry)
+}
+
+func TestCityGeoJSONFeature(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	record, err := reader.City(netip.MustParseAddr("81.2.69.160"))
+	require.NoError(t, err)
+
+	feature, err := record.GeoJSONFeature()
+	require.NoError(t, err)
+	assert.Equal(t, "Feature", feature.Type)
+	require.NotNil(t, feature.Geometry)
+	assert.Equal(t, record.Location.GeoJSONGeometry(), feature.Geometry)
+
+	assert.Equal(t, "GB", feature.Properties["country.iso_code"])
+	assert.Equal(t, "London", feature.Properties["city.names.en"])
+	assert.Equal(t, json.Number("2643743"), feature.Properties["city.geoname_id"])
+	assert.Equal(t, "ENG", feature.Properties["subdivisions.0.iso_code"])
+	assert.Equal(t, "81.2.69.160", feature.Properties["traits.ip_address"])
+
+	data, err := json.Marshal(feature)
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "Feature", decoded["type"])
+}
+
+func TestEnterpriseGeoJSONFeature(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-Enterprise-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	record, err := reader.Enterprise(netip.MustParseAddr("81.2.69.160"))
+	require.NoError(t, err)
+
+	feature, err := record.GeoJSONFeature(CircleSegments(0))
+	require.NoError(t, err)
+	require.NotNil(t, feature.Geometry)
+	assert.Equal(t, "Point", feature.Geometry.Type)
+	assert.Equal(t, "GB", feature.Properties["country.iso_code"])
+}
+
+func TestGeoJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewGeoJSONWriter(&buf)
+	require.NoError(t, writer.Close())
+	assert.JSONEq(t, `{"type":"FeatureCollection","features":[]}`, buf.String())
+
+	buf.Reset()
+	writer = NewGeoJSONWriter(&buf)
+	for _, name := range []string{"a", "b"} {
+		require.NoError(t, writer.Write(GeoJSONFeature{
+			Type:       "Feature",
+			Properties: map[string]any{"name": name},
+		}))
+	}
+	require.NoError(t, writer.Close())
+	require.NoError(t, writer.Close())
+	assert.JSONEq(t, `{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":null,"properties":{"name":"a"}},
+		{"type":"Feature","geometry":null,"properties":{"name":"b"}}
+	]}`, buf.String())
+
+	require.ErrorIs(t, writer.Write(GeoJSONFeature{}), errGeoJSONWriterClosed)
+}
+
+func TestReaderWriteGeoJSON(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	var buf bytes.Buffer
+	require.NoError(t, reader.WriteGeoJSON(&buf, CircleSegments(16)))
+
+	var collection struct {
+		Type     string           `json:"type"`
+		Features []GeoJSONFeature `json:"features"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &collection))
+	assert.Equal(t, "FeatureCollection", collection.Type)
+	require.NotEmpty(t, collection.Features)
+	for _, feature := range collection.Features {
+		assert.Equal(t, "Feature", feature.Type)
+		require.NotNil(t, feature.Geometry)
+		assert.Contains(t, feature.Properties, "traits.network")
+	}
+
+	buf.Reset()
+	require.NoError(t, reader.WriteGeoJSONWithin(&buf, netip.MustParsePrefix("81.2.69.0/24")))
+	collection.Features = nil
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &collection))
+	require.NotEmpty(t, collection.Features)
+	for _, feature := range collection.Features {
+		network := netip.MustParsePrefix(feature.Properties["traits.network"].(string))
+		assert.True(t, netip.MustParsePrefix("81.2.69.0/24").Overlaps(network), network)
+	}
+
+	asn, err := Open("test-data/test-data/GeoLite2-ASN-Test.mmdb")
+	require.NoError(t, err)
+	defer asn.Close()
+
+	err = asn.WriteGeoJSON(&buf)
+	var methodErr InvalidMethodError
+	require.ErrorAs(t, err, &methodErr)
+}
@@ -0,0 +1,168 @@
+package geoip2
+
+import (
+	"net/netip"
+	"reflect"
+	"sync"
+)
+
+// Method identifies one of the lookup methods of Reader. Lookup uses it to
+// only allow lookups on the databases supported by that method.
+type Method int
+
+// The methods that may be passed to Lookup.
+const (
+	MethodAnonymousIP    = Method(isAnonymousIP)
+	MethodASN            = Method(isASN)
+	MethodCity           = Method(isCity)
+	MethodConnectionType = Method(isConnectionType)
+	MethodCountry        = Method(isCountry)
+	MethodDomain         = Method(isDomain)
+	MethodEnterprise     = Method(isEnterprise)
+	MethodISP            = Method(isISP)
+	MethodAnonymousPlus  = Method(isAnonymousPlus)
+)
+
+// String returns the name of the Reader method, e.g., "City".
+func (m Method) String() string {
+	switch m {
+	case MethodAnonymousIP:
+		return "AnonymousIP"
+	case MethodAnonymousPlus:
+		return "AnonymousPlus"
+	case MethodASN:
+		return "ASN"
+	case MethodCity:
+		return "City"
+	case MethodConnectionType:
+		return "ConnectionType"
+	case MethodCountry:
+		return "Country"
+	case MethodDomain:
+		return "Domain"
+	case MethodEnterprise:
+		return "Enterprise"
+	case MethodISP:
+		return "ISP"
+	default:
+		return "Unknown"
+	}
+}
+
+// Method returns the Method for the most detailed record type provided by the
+// database, e.g., MethodEnterprise for an Enterprise database, which also
+// supports City and Country lookups, or MethodISP for an ISP database. City
+// and Country databases both return MethodCity.
+func (r *Reader) Method() Method {
+	return Method(r.recordType())
+}
+
+// Lookup takes an IP address as a netip.Addr and decodes the record for it
+// into a new value of type T, which should be a struct using maxminddb tags
+// in the same way as the structs returned by the Reader methods. This allows
+// decoding only the fields you need, e.g.:
+//
+//	type record struct {
+//		Country struct {
+//			ISOCode string `maxminddb:"iso_code"`
+//		} `maxminddb:"country"`
+//		Traits struct {
+//			Network   netip.Prefix
+//			IsAnycast bool `maxminddb:"is_anycast"`
+//		} `maxminddb:"traits"`
+//	}
+//
+//	rec, err := geoip2.Lookup[record](db, ip, geoip2.MethodCountry)
+//
+// The lookup is only allowed on the databases supported by the Reader method
+// identified by method. Otherwise, an InvalidMethodError is returned.
+//
+// If T has a netip.Addr field named IPAddress or a netip.Prefix field named
+// Network, either directly or in a struct field named Traits, it is set to
+// the IP address used during the lookup or the network of the record,
+// respectively.
+func Lookup[T any](r *Reader, ipAddress netip.Addr, method Method) (*T, error) {
+	if databaseType(method)&r.databaseType == 0 {
+		return nil, InvalidMethodError{method.String(), r.Metadata().DatabaseType}
+	}
+	result := r.mmdbReader.Lookup(ipAddress)
+	if err := r.checkFound(ipAddress, result); err != nil {
+		return nil, err
+	}
+	var val T
+	err := r.decode(result, &val)
+	if err != nil {
+		return &val, err
+	}
+	setNetworkFields(reflect.ValueOf(&val).Elem(), ipAddress, result.Prefix())
+	return &val, nil
+}
+
+var (
+	addrType   = reflect.TypeFor[netip.Addr]()
+	prefixType = reflect.TypeFor[netip.Prefix]()
+
+	// networkFieldsCache maps a reflect.Type to its networkFields.
+	networkFieldsCache sync.Map
+)
+
+// networkFields holds the indexes of the IPAddress and Network fields of a
+// struct type, if it has them.
+type networkFields struct {
+	ipAddress []int
+	network   []int
+}
+
+func setNetworkFields(v reflect.Value, ipAddress netip.Addr, network netip.Prefix) {
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	fields := cachedNetworkFields(v.Type())
+	if fields.ipAddress != nil {
+		v.FieldByIndex(fields.ipAddress).Set(reflect.ValueOf(ipAddress))
+	}
+	if fields.network != nil {
+		v.FieldByIndex(fields.network).Set(reflect.ValueOf(network))
+	}
+}
+
+func cachedNetworkFields(t reflect.Type) networkFields {
+	if fields, ok := networkFieldsCache.Load(t); ok {
+		return fields.(networkFields)
+	}
+	fields := findNetworkFields(t)
+	networkFieldsCache.Store(t, fields)
+	return fields
+}
+
+func findNetworkFields(t reflect.Type) networkFields {
+	var fields networkFields
+	if f, ok := directField(t, "IPAddress"); ok && f.Type == addrType {
+		fields.ipAddress = f.Index
+	}
+	if f, ok := directField(t, "Network"); ok && f.Type == prefixType {
+		fields.network = f.Index
+	}
+	traits, ok := directField(t, "Traits")
+	if !ok || traits.Type.Kind() != reflect.Struct {
+		return fields
+	}
+	traitsFields := findNetworkFields(traits.Type)
+	if fields.ipAddress == nil && traitsFields.ipAddress != nil {
+		fields.ipAddress = append(append([]int{}, traits.Index...), traitsFields.ipAddress...)
+	}
+	if fields.network == nil && traitsFields.network != nil {
+		fields.network = append(append([]int{}, traits.Index...), traitsFields.network...)
+	}
+	return fields
+}
+
+// directField returns the exported field of the struct type t with the
+// provided name, excluding fields promoted from embedded structs.
+func directField(t reflect.Type, name string) (reflect.StructField, bool) {
+	f, ok := t.FieldByName(name)
+	if !ok || !f.IsExported() || len(f.Index) != 1 {
+		return reflect.StructField{}, false
+	}
+	return f, true
+}
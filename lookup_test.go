@@ -0,0 +1,98 @@
+package geoip2
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countryAndAnycast struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Traits struct {
+		Network   netip.Prefix
+		IsAnycast bool `maxminddb:"is_anycast"`
+	} `maxminddb:"traits"`
+	IPAddress netip.Addr
+}
+
+func TestLookup(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	testAddr := netip.MustParseAddr("81.2.69.160")
+	record, err := Lookup[countryAndAnycast](reader, testAddr, MethodCountry)
+	require.NoError(t, err)
+
+	assert.Equal(t, "GB", record.Country.ISOCode)
+	assert.False(t, record.Traits.IsAnycast)
+	assert.Equal(t, testAddr, record.IPAddress)
+	assert.True(t, record.Traits.Network.IsValid())
+	assert.True(t, record.Traits.Network.Contains(testAddr))
+
+	record, err = Lookup[countryAndAnycast](reader, netip.MustParseAddr("214.1.1.0"), MethodCity)
+	require.NoError(t, err)
+	assert.True(t, record.Traits.IsAnycast)
+
+	// The package's own structs may be used as well.
+	city, err := Lookup[City](reader, testAddr, MethodCity)
+	require.NoError(t, err)
+	expected, err := reader.City(testAddr)
+	require.NoError(t, err)
+	assert.Equal(t, expected, city)
+}
+
+func TestLookupTopLevelNetwork(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoLite2-ASN-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	type asnOnly struct {
+		Network                netip.Prefix
+		AutonomousSystemNumber uint `maxminddb:"autonomous_system_number"`
+	}
+
+	testAddr := netip.MustParseAddr("1.128.0.0")
+	record, err := Lookup[asnOnly](reader, testAddr, MethodASN)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1221), record.AutonomousSystemNumber)
+	assert.True(t, record.Network.Contains(testAddr))
+}
+
+func TestLookupInvalidMethod(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoLite2-ASN-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	_, err = Lookup[countryAndAnycast](reader, netip.MustParseAddr("1.128.0.0"), MethodCountry)
+	require.ErrorAs(t, err, &InvalidMethodError{})
+	assert.Equal(
+		t,
+		"geoip2: the Country method does not support the GeoLite2-ASN database",
+		err.Error(),
+	)
+}
+
+func TestReaderMethod(t *testing.T) {
+	tests := map[string]Method{
+		"GeoIP-Anonymous-Plus-Test.mmdb":   MethodAnonymousPlus,
+		"GeoIP2-Anonymous-IP-Test.mmdb":    MethodAnonymousIP,
+		"GeoIP2-City-Test.mmdb":            MethodCity,
+		"GeoIP2-Connection-Type-Test.mmdb": MethodConnectionType,
+		"GeoIP2-Country-Test.mmdb":         MethodCity,
+		"GeoIP2-Domain-Test.mmdb":          MethodDomain,
+		"GeoIP2-Enterprise-Test.mmdb":      MethodEnterprise,
+		"GeoIP2-ISP-Test.mmdb":             MethodISP,
+		"GeoLite2-ASN-Test.mmdb":           MethodASN,
+	}
+	for file, expected := range tests {
+		reader, err := Open("test-data/test-data/" + file)
+		require.NoError(t, err)
+		assert.Equal(t, expected, reader.Method(), file)
+		require.NoError(t, reader.Close())
+	}
+}
@@ -1,6 +1,9 @@
 package geoip2
 
-import "net/netip"
+import (
+	"math"
+	"net/netip"
+)
 
 // Names contains localized names for geographic entities.
 type Names struct {
@@ -20,6 +23,10 @@ type Names struct {
 	Russian string `json:"ru,omitzero"    maxminddb:"ru"`
 	// SimplifiedChinese localized name (zh-CN)
 	SimplifiedChinese string `json:"zh-CN,omitzero" maxminddb:"zh-CN"` //nolint:tagliatelle // zh-CN matches MMDB format
+	// Other contains the localized names for the locales without a field
+	// above, e.g., "ko" or "zh-TW". Only the locales listed in the database
+	// metadata are decoded. It is nil if there are none.
+	Other *OtherNames `json:"-"              maxminddb:"-"`
 }
 
 var (
@@ -96,6 +103,28 @@ func (l Location) HasCoordinates() bool {
 	return l.Latitude != nil && l.Longitude != nil
 }
 
+// allocateCoordinates points the coordinates of l at a newly allocated pair
+// for the decoder to fill in, which allocates once rather than once per
+// coordinate. The coordinates are set to NaN, which is never a valid
+// coordinate, so that clearMissingCoordinates can tell which of them were not
+// present in the decoded record.
+func (l *Location) allocateCoordinates() {
+	coordinates := &[2]float64{math.NaN(), math.NaN()}
+	l.Latitude = &coordinates[0]
+	l.Longitude = &coordinates[1]
+}
+
+// clearMissingCoordinates sets the coordinates that were not decoded after
+// calling allocateCoordinates back to nil.
+func (l *Location) clearMissingCoordinates() {
+	if l.Latitude != nil && math.IsNaN(*l.Latitude) {
+		l.Latitude = nil
+	}
+	if l.Longitude != nil && math.IsNaN(*l.Longitude) {
+		l.Longitude = nil
+	}
+}
+
 // RepresentedCountry contains data for the represented country associated
 // with an IP address. The represented country is the country represented
 // by something like a military base or embassy.
@@ -507,6 +536,53 @@ func (a AnonymousIP) HasData() bool {
 		a.IsPublicProxy || a.IsResidentialProxy || a.IsTorExitNode
 }
 
+// The AnonymousPlus struct corresponds to the data in the GeoIP Anonymous
+// Plus database. In addition to the data in the GeoIP2 Anonymous IP
+// database, it provides the name of the anonymizer, our confidence that the
+// network is anonymized, and when the network was last seen.
+type AnonymousPlus struct {
+	// IPAddress is the IP address used during the lookup
+	IPAddress netip.Addr `json:"ip_address,omitzero"`
+	// Network is the largest network prefix where all fields besides
+	// IPAddress have the same value.
+	Network netip.Prefix `json:"network,omitzero"`
+	// NetworkLastSeen is the last day the network was sighted in our analysis
+	// of anonymized networks, in the YYYY-MM-DD format.
+	NetworkLastSeen string `json:"network_last_seen,omitzero"     maxminddb:"network_last_seen"`
+	// ProviderName is the name of the VPN provider, e.g., NordVPN or
+	// SurfShark, associated with the network.
+	ProviderName string `json:"provider_name,omitzero"         maxminddb:"provider_name"`
+	// AnonymizerConfidence is a score ranging from 1 to 99 that is our
+	// percent confidence that the network is currently part of an actively
+	// used VPN service.
+	AnonymizerConfidence uint16 `json:"anonymizer_confidence,omitzero" maxminddb:"anonymizer_confidence"`
+	// IsAnonymous is true if the IP address belongs to any sort of anonymous network.
+	IsAnonymous bool `json:"is_anonymous,omitzero"          maxminddb:"is_anonymous"`
+	// IsAnonymousVPN is true if the IP address is registered to an anonymous
+	// VPN provider. If a VPN provider does not register subnets under names
+	// associated with them, we will likely only flag their IP ranges using the
+	// IsHostingProvider attribute.
+	IsAnonymousVPN bool `json:"is_anonymous_vpn,omitzero"      maxminddb:"is_anonymous_vpn"`
+	// IsHostingProvider is true if the IP address belongs to a hosting or VPN provider
+	// (see description of IsAnonymousVPN attribute).
+	IsHostingProvider bool `json:"is_hosting_provider,omitzero"   maxminddb:"is_hosting_provider"`
+	// IsPublicProxy is true if the IP address belongs to a public proxy.
+	IsPublicProxy bool `json:"is_public_proxy,omitzero"       maxminddb:"is_public_proxy"`
+	// IsResidentialProxy is true if the IP address is on a suspected
+	// anonymizing network and belongs to a residential ISP.
+	IsResidentialProxy bool `json:"is_residential_proxy,omitzero"  maxminddb:"is_residential_proxy"`
+	// IsTorExitNode is true if the IP address is a Tor exit node.
+	IsTorExitNode bool `json:"is_tor_exit_node,omitzero"      maxminddb:"is_tor_exit_node"`
+}
+
+// HasData returns true if any data was found for the IP in the AnonymousPlus database.
+// This excludes the Network and IPAddress fields which are always populated for found IPs.
+func (a AnonymousPlus) HasData() bool {
+	return a.NetworkLastSeen != "" || a.ProviderName != "" || a.AnonymizerConfidence != 0 ||
+		a.IsAnonymous || a.IsAnonymousVPN || a.IsHostingProvider ||
+		a.IsPublicProxy || a.IsResidentialProxy || a.IsTorExitNode
+}
+
 // The ASN struct corresponds to the data in the GeoLite2 ASN database.
 type ASN struct {
 	// IPAddress is the IP address used during the lookup
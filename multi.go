@@ -0,0 +1,146 @@
+package geoip2
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+)
+
+// MultiRecord combines the records found for an IP address in each of the
+// databases of a MultiReader. A field is nil if none of the databases
+// provides that type of record. Each record keeps the Network found in its
+// own database, as these generally differ between databases.
+type MultiRecord struct {
+	// IPAddress is the IP address used during the lookup
+	IPAddress netip.Addr `json:"ip_address,omitzero"`
+	// Enterprise is the record from the GeoIP2 Enterprise database.
+	Enterprise *Enterprise `json:"enterprise,omitzero"`
+	// City is the record from the City or Country database.
+	City *City `json:"city,omitzero"`
+	// ISP is the record from the GeoIP2 ISP database.
+	ISP *ISP `json:"isp,omitzero"`
+	// ASN is the record from the GeoLite2 ASN database.
+	ASN *ASN `json:"asn,omitzero"`
+	// AnonymousIP is the record from the GeoIP2 Anonymous IP database.
+	AnonymousIP *AnonymousIP `json:"anonymous_ip,omitzero"`
+	// AnonymousPlus is the record from the GeoIP Anonymous Plus database.
+	AnonymousPlus *AnonymousPlus `json:"anonymous_plus,omitzero"`
+	// ConnectionType is the record from the GeoIP2 Connection-Type database.
+	ConnectionType *ConnectionType `json:"connection_type,omitzero"`
+	// Domain is the record from the GeoIP2 Domain database.
+	Domain *Domain `json:"domain,omitzero"`
+}
+
+// HasData returns true if any data was found for the IP in any of the
+// databases. This excludes the Network and IPAddress fields which are always
+// populated for found IPs.
+func (m MultiRecord) HasData() bool {
+	return (m.Enterprise != nil && m.Enterprise.HasData()) ||
+		(m.City != nil && m.City.HasData()) ||
+		(m.ISP != nil && m.ISP.HasData()) ||
+		(m.ASN != nil && m.ASN.HasData()) ||
+		(m.AnonymousIP != nil && m.AnonymousIP.HasData()) ||
+		(m.AnonymousPlus != nil && m.AnonymousPlus.HasData()) ||
+		(m.ConnectionType != nil && m.ConnectionType.HasData()) ||
+		(m.Domain != nil && m.Domain.HasData())
+}
+
+// MultiReader looks up an IP address in several databases at once, e.g., a
+// City, an ASN, and an Anonymous IP database, and combines the results into a
+// single MultiRecord.
+type MultiReader struct {
+	readers []*Reader
+}
+
+// NewMultiReader returns a MultiReader that performs lookups on the provided
+// readers. Each reader is used for the most detailed record type its
+// database supports: Enterprise databases fill in MultiRecord.Enterprise,
+// City and Country databases MultiRecord.City, ISP databases
+// MultiRecord.ISP, and so on. An error is returned if two of the databases
+// provide the same record type.
+//
+// The MultiReader takes ownership of the readers. Use the Close method on
+// the MultiReader to close all of them.
+func NewMultiReader(readers ...*Reader) (*MultiReader, error) {
+	if len(readers) == 0 {
+		return nil, errors.New("geoip2: at least one reader is required")
+	}
+	seen := map[databaseType]*Reader{}
+	for _, reader := range readers {
+		recordType := reader.recordType()
+		if other, ok := seen[recordType]; ok {
+			return nil, fmt.Errorf(
+				"geoip2: the %s and %s databases provide the same record type",
+				other.Metadata().DatabaseType,
+				reader.Metadata().DatabaseType,
+			)
+		}
+		seen[recordType] = reader
+	}
+	return &MultiReader{readers: readers}, nil
+}
+
+// recordType returns the most detailed record type supported by the
+// database.
+func (r *Reader) recordType() databaseType {
+	switch {
+	case r.databaseType&isEnterprise != 0:
+		return isEnterprise
+	case r.databaseType&isCity != 0:
+		return isCity
+	case r.databaseType&isISP != 0:
+		return isISP
+	case r.databaseType&isAnonymousPlus != 0:
+		return isAnonymousPlus
+	default:
+		return r.databaseType
+	}
+}
+
+// Lookup takes an IP address as a netip.Addr and returns a MultiRecord
+// struct and/or an error. If a lookup fails, the error is returned along with
+// the records found in the preceding databases. For readers opened with
+// StrictLookups, an AddressNotFoundError or ReservedAddressError does not
+// fail the lookup; the field of the database is left nil instead.
+func (m *MultiReader) Lookup(ipAddress netip.Addr) (*MultiRecord, error) {
+	record := &MultiRecord{IPAddress: ipAddress}
+	for _, reader := range m.readers {
+		var err error
+		switch reader.recordType() {
+		case isEnterprise:
+			record.Enterprise, err = reader.Enterprise(ipAddress)
+		case isCity:
+			record.City, err = reader.City(ipAddress)
+		case isISP:
+			record.ISP, err = reader.ISP(ipAddress)
+		case isASN:
+			record.ASN, err = reader.ASN(ipAddress)
+		case isAnonymousIP:
+			record.AnonymousIP, err = reader.AnonymousIP(ipAddress)
+		case isAnonymousPlus:
+			record.AnonymousPlus, err = reader.AnonymousPlus(ipAddress)
+		case isConnectionType:
+			record.ConnectionType, err = reader.ConnectionType(ipAddress)
+		case isDomain:
+			record.Domain, err = reader.Domain(ipAddress)
+		}
+		var notFound AddressNotFoundError
+		var reserved ReservedAddressError
+		if errors.As(err, &notFound) || errors.As(err, &reserved) {
+			continue
+		}
+		if err != nil {
+			return record, err
+		}
+	}
+	return record, nil
+}
+
+// Close closes all of the readers of the MultiReader.
+func (m *MultiReader) Close() error {
+	var errs []error
+	for _, reader := range m.readers {
+		errs = append(errs, reader.Close())
+	}
+	return errors.Join(errs...)
+}
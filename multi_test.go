@@ -0,0 +1,137 @@
+package geoip2
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openMultiReader(t *testing.T, files ...string) *MultiReader {
+	t.Helper()
+
+	readers := make([]*Reader, 0, len(files))
+	for _, file := range files {
+		reader, err := Open("test-data/test-data/" + file)
+		require.NoError(t, err)
+		readers = append(readers, reader)
+	}
+	multi, err := NewMultiReader(readers...)
+	require.NoError(t, err)
+	return multi
+}
+
+func TestMultiReader(t *testing.T) {
+	reader := openMultiReader(
+		t,
+		"GeoIP2-City-Test.mmdb",
+		"GeoLite2-ASN-Test.mmdb",
+		"GeoIP2-Anonymous-IP-Test.mmdb",
+		"GeoIP2-Connection-Type-Test.mmdb",
+		"GeoIP2-Domain-Test.mmdb",
+	)
+	defer reader.Close()
+
+	testAddr := netip.MustParseAddr("81.2.69.160")
+	record, err := reader.Lookup(testAddr)
+	require.NoError(t, err)
+	assert.True(t, record.HasData())
+	assert.Equal(t, testAddr, record.IPAddress)
+
+	require.NotNil(t, record.City)
+	assert.Equal(t, "London", record.City.City.Names.English)
+	assert.True(t, record.City.Traits.Network.Contains(testAddr))
+
+	require.NotNil(t, record.ASN)
+	assert.Equal(t, testAddr, record.ASN.IPAddress)
+	assert.True(t, record.ASN.Network.Contains(testAddr))
+
+	require.NotNil(t, record.AnonymousIP)
+	require.NotNil(t, record.ConnectionType)
+	require.NotNil(t, record.Domain)
+	assert.Nil(t, record.Enterprise)
+	assert.Nil(t, record.ISP)
+
+	testAddr = netip.MustParseAddr("1.128.0.0")
+	record, err = reader.Lookup(testAddr)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1221), record.ASN.AutonomousSystemNumber)
+	assert.Equal(t, "Telstra Pty Ltd", record.ASN.AutonomousSystemOrganization)
+	assert.True(t, record.ASN.Network.Contains(testAddr))
+
+	record, err = reader.Lookup(netip.MustParseAddr("1.2.0.0"))
+	require.NoError(t, err)
+	assert.True(t, record.AnonymousIP.IsAnonymousVPN)
+	assert.Equal(t, "maxmind.com", record.Domain.Domain)
+
+	record, err = reader.Lookup(netip.MustParseAddr("1.0.1.0"))
+	require.NoError(t, err)
+	assert.Equal(t, "Cellular", record.ConnectionType.ConnectionType)
+}
+
+func TestMultiReaderStrictLookups(t *testing.T) {
+	var readers []*Reader
+	for _, file := range []string{"GeoIP2-City-Test.mmdb", "GeoLite2-ASN-Test.mmdb"} {
+		reader, err := Open("test-data/test-data/"+file, StrictLookups())
+		require.NoError(t, err)
+		readers = append(readers, reader)
+	}
+	reader, err := NewMultiReader(readers...)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	// The address is only in the ASN database, which comes second.
+	record, err := reader.Lookup(netip.MustParseAddr("1.128.0.0"))
+	require.NoError(t, err)
+	assert.Nil(t, record.City)
+	require.NotNil(t, record.ASN)
+	assert.Equal(t, uint(1221), record.ASN.AutonomousSystemNumber)
+
+	record, err = reader.Lookup(netip.MustParseAddr("81.2.69.160"))
+	require.NoError(t, err)
+	require.NotNil(t, record.City)
+	assert.Equal(t, "London", record.City.City.Names.English)
+	assert.Nil(t, record.ASN)
+
+	record, err = reader.Lookup(netip.MustParseAddr("10.0.0.1"))
+	require.NoError(t, err)
+	assert.Nil(t, record.City)
+	assert.Nil(t, record.ASN)
+	assert.False(t, record.HasData())
+}
+
+func TestMultiReaderEnterpriseAndISP(t *testing.T) {
+	reader := openMultiReader(t, "GeoIP2-Enterprise-Test.mmdb", "GeoIP2-ISP-Test.mmdb")
+	defer reader.Close()
+
+	record, err := reader.Lookup(netip.MustParseAddr("149.101.100.0"))
+	require.NoError(t, err)
+
+	require.NotNil(t, record.Enterprise)
+	assert.Equal(t, "Verizon Wireless", record.Enterprise.Traits.ISP)
+	require.NotNil(t, record.ISP)
+	assert.Equal(t, "310", record.ISP.MobileCountryCode)
+	assert.Nil(t, record.City)
+	assert.Nil(t, record.ASN)
+}
+
+func TestNewMultiReaderErrors(t *testing.T) {
+	_, err := NewMultiReader()
+	require.EqualError(t, err, "geoip2: at least one reader is required")
+
+	city, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer city.Close()
+
+	country, err := Open("test-data/test-data/GeoIP2-Country-Test.mmdb")
+	require.NoError(t, err)
+	defer country.Close()
+
+	_, err = NewMultiReader(city, country)
+	require.EqualError(
+		t,
+		err,
+		"geoip2: the GeoIP2-City and GeoIP2-Country databases provide the same record type",
+	)
+}
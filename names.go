@@ -0,0 +1,417 @@
+package geoip2
+
+import (
+	"encoding/json"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang/v2"
+)
+
+// namesLocales are the locales of the fields of Names, in field order.
+var namesLocales = []string{"de", "en", "es", "fr", "ja", "pt-BR", "ru", "zh-CN"}
+
+// Get returns the name for the BCP 47 language tag locale, e.g., "pt-BR", or
+// the empty string if there is no name for it. The tag is matched exactly,
+// ignoring case. Use Best for fallback between related tags.
+func (n Names) Get(locale string) string {
+	if field := n.field(locale); field != nil {
+		return *field
+	}
+	return n.Other.Get(locale)
+}
+
+// OtherNames holds the localized names for locales without a field in Names.
+// It is immutable, so that Names holding a pointer to it remain comparable
+// and may be copied freely. A nil *OtherNames holds no names.
+type OtherNames struct {
+	names map[string]string
+}
+
+// NewOtherNames returns an OtherNames holding a copy of names, keyed by
+// locale, or nil if names is empty.
+func NewOtherNames(names map[string]string) *OtherNames {
+	if len(names) == 0 {
+		return nil
+	}
+	return &OtherNames{names: maps.Clone(names)}
+}
+
+// Get returns the name for the BCP 47 language tag locale, or the empty
+// string if there is no name for it. The tag is matched exactly, ignoring
+// case.
+func (o *OtherNames) Get(locale string) string {
+	if o == nil {
+		return ""
+	}
+	if name, ok := o.names[locale]; ok {
+		return name
+	}
+	for other, name := range o.names {
+		if strings.EqualFold(other, locale) {
+			return name
+		}
+	}
+	return ""
+}
+
+// Len returns the number of names.
+func (o *OtherNames) Len() int {
+	if o == nil {
+		return 0
+	}
+	return len(o.names)
+}
+
+// All returns an iterator over the locales and names, in sorted order of
+// locale.
+func (o *OtherNames) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for _, locale := range o.locales() {
+			if !yield(locale, o.names[locale]) {
+				return
+			}
+		}
+	}
+}
+
+// locales returns the locales of the names in sorted order.
+func (o *OtherNames) locales() []string {
+	if o == nil {
+		return nil
+	}
+	return slices.Sorted(maps.Keys(o.names))
+}
+
+// field returns a pointer to the field of n for locale, ignoring case, or nil
+// if there is no such field.
+func (n *Names) field(locale string) *string {
+	switch strings.ToLower(locale) {
+	case "de":
+		return &n.German
+	case "en":
+		return &n.English
+	case "es":
+		return &n.Spanish
+	case "fr":
+		return &n.French
+	case "ja":
+		return &n.Japanese
+	case "pt-br":
+		return &n.BrazilianPortuguese
+	case "ru":
+		return &n.Russian
+	case "zh-cn":
+		return &n.SimplifiedChinese
+	default:
+		return nil
+	}
+}
+
+// set sets the name for locale, storing it in Other if there is no field for
+// locale. As OtherNames is immutable, Other is replaced with a copy holding
+// the name.
+func (n *Names) set(locale, name string) {
+	if field := n.field(locale); field != nil {
+		*field = name
+		return
+	}
+	other := &OtherNames{names: map[string]string{locale: name}}
+	if n.Other != nil {
+		other.names = maps.Clone(n.Other.names)
+		other.names[locale] = name
+	}
+	n.Other = other
+}
+
+// locales returns the locales of the fields of n followed by those in
+// n.Other, in sorted order.
+func (n Names) locales() []string {
+	if n.Other.Len() == 0 {
+		return namesLocales
+	}
+	return append(slices.Clone(namesLocales), n.Other.locales()...)
+}
+
+// namedPlaces are the keys of the places with names in City, Enterprise, and
+// Country records, other than the subdivisions.
+var namedPlaces = [...]string{
+	"continent",
+	"city",
+	"country",
+	"registered_country",
+	"represented_country",
+}
+
+// otherLocale is a locale listed in the metadata of a database that has no
+// field in Names. paths are the paths of its names for namedPlaces, built
+// once so that looking up the names does not allocate them.
+type otherLocale struct {
+	locale string
+	// key is locale as a path element, boxed once so that looking up the
+	// names of subdivisions does not allocate it.
+	key   any
+	paths [len(namedPlaces)][]any
+}
+
+// otherLocales returns the languages, those listed in the metadata of a
+// database, that have no field in Names.
+func otherLocales(languages []string) []otherLocale {
+	var n Names
+	var locales []otherLocale
+	for _, language := range languages {
+		if n.field(language) != nil {
+			continue
+		}
+		locale := otherLocale{locale: language, key: language}
+		for i, place := range namedPlaces {
+			locale.paths[i] = []any{place, "names", language}
+		}
+		locales = append(locales, locale)
+	}
+	return locales
+}
+
+// decodeOtherNames sets the Other field of the Names of record, if it is a
+// *City, *Enterprise, or *Country, from the names in result for the locales
+// listed in the metadata that have no field in Names.
+//
+// The fields of Names are decoded from their struct tags, which is much
+// cheaper than decoding the names as a map. The other names are therefore
+// looked up one by one, and only if the metadata lists such locales.
+func (r *Reader) decodeOtherNames(result maxminddb.Result, record any) error {
+	if len(r.otherLocales) == 0 {
+		return nil
+	}
+	// places are the Names of record for namedPlaces, nil if it has no such
+	// place.
+	var places [len(namedPlaces)]*Names
+	// subdivision returns the Names of the subdivision i of record, of which
+	// there are subdivisions.
+	var (
+		subdivision  func(i int) *Names
+		subdivisions int
+	)
+	switch record := record.(type) {
+	case *City:
+		places = [...]*Names{
+			&record.Continent.Names,
+			&record.City.Names,
+			&record.Country.Names,
+			&record.RegisteredCountry.Names,
+			&record.RepresentedCountry.Names,
+		}
+		subdivision = func(i int) *Names { return &record.Subdivisions[i].Names }
+		subdivisions = len(record.Subdivisions)
+	case *Enterprise:
+		places = [...]*Names{
+			&record.Continent.Names,
+			&record.City.Names,
+			&record.Country.Names,
+			&record.RegisteredCountry.Names,
+			&record.RepresentedCountry.Names,
+		}
+		subdivision = func(i int) *Names { return &record.Subdivisions[i].Names }
+		subdivisions = len(record.Subdivisions)
+	case *Country:
+		places = [...]*Names{
+			&record.Continent.Names,
+			nil,
+			&record.Country.Names,
+			&record.RegisteredCountry.Names,
+			&record.RepresentedCountry.Names,
+		}
+	default:
+		return nil
+	}
+
+	var name string
+	for _, locale := range r.otherLocales {
+		for i, n := range places {
+			if n == nil {
+				continue
+			}
+			name = ""
+			if err := result.DecodePath(&name, locale.paths[i]...); err != nil {
+				return err
+			}
+			if name != "" {
+				n.set(locale.locale, name)
+			}
+		}
+		for i := range subdivisions {
+			name = ""
+			err := result.DecodePath(&name, "subdivisions", i, "names", locale.key)
+			if err != nil {
+				return err
+			}
+			if name != "" {
+				subdivision(i).set(locale.locale, name)
+			}
+		}
+	}
+	return nil
+}
+
+// namesFields is Names without its methods, for the default JSON encoding.
+type namesFields Names
+
+// MarshalJSON implements json.Marshaler. The names in Other are included
+// alongside those of the other fields, keyed by locale.
+func (n Names) MarshalJSON() ([]byte, error) {
+	if n.Other.Len() == 0 {
+		return json.Marshal(namesFields(n))
+	}
+	names := make(map[string]string, n.Other.Len()+len(namesLocales))
+	for locale, name := range n.Other.All() {
+		names[locale] = name
+	}
+	for _, locale := range namesLocales {
+		if name := n.Get(locale); name != "" {
+			names[locale] = name
+		}
+	}
+	return json.Marshal(names)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Names for locales without a
+// field are stored in Other.
+func (n *Names) UnmarshalJSON(data []byte) error {
+	var names map[string]string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	for locale, name := range names {
+		n.set(locale, name)
+	}
+	return nil
+}
+
+// Best returns the name for the first of locales, a list of BCP 47 language
+// tags in order of preference, for which there is a name, e.g.:
+//
+//	name := record.City.Names.Best("pt-BR", "es", "en")
+//
+// Each tag is matched as follows, stopping at the first match:
+//
+//  1. the tag itself, e.g., "zh-Hant-TW";
+//  2. the tag with trailing subtags removed, e.g., "zh-Hant" and "zh";
+//  3. the language and region of the tag, without its script, e.g., "zh-TW";
+//  4. any other locale of the same language, e.g., "zh-HK", unless it is
+//     written in another script. The script of a Chinese locale without a
+//     script subtag is implied by its region, so "zh-CN", which is written in
+//     Simplified Chinese, is not a match for "zh-Hant-TW" or "zh-TW".
+//
+// Tags are compared ignoring case, and "_" is accepted in place of "-". The
+// empty string is returned if there is no name for any of locales.
+func (n Names) Best(locales ...string) string {
+	for _, locale := range locales {
+		tag := strings.ReplaceAll(locale, "_", "-")
+		for prefix := tag; prefix != ""; {
+			if name := n.Get(prefix); name != "" {
+				return name
+			}
+			i := strings.LastIndexByte(prefix, '-')
+			if i < 0 {
+				break
+			}
+			prefix = prefix[:i]
+		}
+
+		language, script, region := parseLocale(tag)
+		if script != "" && region != "" {
+			if name := n.Get(language + "-" + region); name != "" {
+				return name
+			}
+		}
+
+		script = localeScript(language, script, region)
+		for _, available := range n.locales() {
+			availableLanguage, availableScript, availableRegion := parseLocale(available)
+			if !strings.EqualFold(language, availableLanguage) {
+				continue
+			}
+			availableScript = localeScript(availableLanguage, availableScript, availableRegion)
+			if script != "" && availableScript != "" && !strings.EqualFold(script, availableScript) {
+				continue
+			}
+			if name := n.Get(available); name != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// parseLocale returns the language, script, and region subtags of the BCP 47
+// language tag locale. The script and region are empty if locale has none.
+func parseLocale(locale string) (language, script, region string) {
+	subtags := strings.Split(locale, "-")
+	language = subtags[0]
+	for _, subtag := range subtags[1:] {
+		switch {
+		case script == "" && region == "" && len(subtag) == 4 && isAlpha(subtag):
+			script = subtag
+		case region == "" && (len(subtag) == 2 && isAlpha(subtag) ||
+			len(subtag) == 3 && strings.Trim(subtag, "0123456789") == ""):
+			region = subtag
+		default:
+			return language, script, region
+		}
+	}
+	return language, script, region
+}
+
+func isAlpha(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z')
+	}) < 0
+}
+
+// chineseScripts are the scripts implied by the regions of Chinese locales
+// without a script subtag, keyed by lowercase region.
+var chineseScripts = map[string]string{
+	"cn": "Hans",
+	"sg": "Hans",
+	"my": "Hans",
+	"tw": "Hant",
+	"hk": "Hant",
+	"mo": "Hant",
+}
+
+// localeScript returns the script of a locale with the provided subtags: its
+// script subtag or, for Chinese, the script implied by its region. It returns
+// the empty string if neither is known.
+func localeScript(language, script, region string) string {
+	if script != "" || !strings.EqualFold(language, "zh") {
+		return script
+	}
+	return chineseScripts[strings.ToLower(region)]
+}
+
+// readerLocales returns the locales used by a Reader: those set with the
+// Locales option, followed by English, in which names are generally
+// available, and the other languages of the database.
+func readerLocales(preferred, languages []string) []string {
+	locales := slices.Clone(preferred)
+	for _, language := range append([]string{"en"}, languages...) {
+		if !slices.Contains(locales, language) {
+			locales = append(locales, language)
+		}
+	}
+	return locales
+}
+
+// Locales returns the locales, in order of preference, used by the
+// LocalizedName method of the Reader. See the Locales option.
+func (r *Reader) Locales() []string {
+	return slices.Clone(r.locales)
+}
+
+// LocalizedName returns the name in names for the preferred locales of the
+// Reader, as Names.Best does. See the Locales option.
+func (r *Reader) LocalizedName(names Names) string {
+	return names.Best(r.locales...)
+}
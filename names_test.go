@@ -0,0 +1,287 @@
+package geoip2
+
+import (
+	"encoding/json"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamesGet(t *testing.T) {
+	names := Names{
+		English:             "Germany",
+		German:              "Deutschland",
+		BrazilianPortuguese: "Alemanha",
+		SimplifiedChinese:   "德国",
+	}
+
+	assert.Equal(t, "Germany", names.Get("en"))
+	assert.Equal(t, "Deutschland", names.Get("DE"))
+	assert.Equal(t, "Alemanha", names.Get("pt-br"))
+	assert.Equal(t, "德国", names.Get("zh-CN"))
+	assert.Empty(t, names.Get("fr"))
+	assert.Empty(t, names.Get("pt"))
+	assert.Empty(t, names.Get("xx"))
+}
+
+func TestNamesBest(t *testing.T) {
+	names := Names{
+		English:             "Germany",
+		Spanish:             "Alemania",
+		BrazilianPortuguese: "Alemanha",
+		SimplifiedChinese:   "德国",
+	}
+
+	tests := []struct {
+		expected string
+		locales  []string
+	}{
+		{"Alemanha", []string{"pt-BR", "es", "en"}},
+		{"Alemania", []string{"fr", "es", "en"}},
+		{"Germany", []string{"fr", "ru", "en"}},
+		{"Germany", []string{"en-US"}},
+		{"Germany", []string{"en_GB"}},
+		{"Alemanha", []string{"pt-PT", "en"}},
+		{"Alemanha", []string{"pt", "en"}},
+		{"德国", []string{"zh-Hans-TW", "en"}},
+		{"德国", []string{"zh", "en"}},
+		{"Germany", []string{"zh-Hant-TW", "en"}},
+		{"Germany", []string{"zh-TW", "en"}},
+		{"Alemania", []string{"ES-mx"}},
+		{"", []string{"fr", "ru"}},
+		{"", nil},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, names.Best(test.locales...), test.locales)
+	}
+}
+
+func TestNamesBestChineseScripts(t *testing.T) {
+	names := Names{
+		SimplifiedChinese: "德国",
+		Other:             NewOtherNames(map[string]string{"zh-TW": "德國"}),
+	}
+
+	tests := []struct {
+		expected string
+		locales  []string
+	}{
+		{"德國", []string{"zh-Hant-TW"}},
+		{"德國", []string{"zh-Hant"}},
+		{"德國", []string{"zh-HK"}},
+		{"德國", []string{"zh-TW"}},
+		{"德国", []string{"zh-Hans-CN"}},
+		{"德国", []string{"zh-Hans"}},
+		{"德国", []string{"zh-SG"}},
+		{"德国", []string{"zh"}},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, names.Best(test.locales...), test.locales)
+	}
+}
+
+func TestReaderLocales(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	locales := reader.Locales()
+	require.NotEmpty(t, locales)
+	assert.Equal(t, "en", locales[0])
+	for _, language := range reader.Metadata().Languages {
+		assert.Contains(t, locales, language)
+	}
+
+	record, err := reader.City(netip.MustParseAddr("81.2.69.160"))
+	require.NoError(t, err)
+	assert.Equal(t, "London", reader.LocalizedName(record.City.Names))
+
+	reader, err = Open(
+		"test-data/test-data/GeoIP2-City-Test.mmdb",
+		Locales("xx", "ru"),
+	)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	assert.Equal(t, []string{"xx", "ru", "en"}, reader.Locales()[:3])
+
+	names := Names{English: "Germany", Russian: "Германия"}
+	assert.Equal(t, "Германия", reader.LocalizedName(names))
+	assert.Equal(t, "Germany", reader.LocalizedName(Names{English: "Germany"}))
+}
+
+// mmdbString encodes s as a MaxMind DB string of less than 29 bytes.
+func mmdbString(s string) []byte {
+	return append([]byte{0x40 | byte(len(s))}, s...)
+}
+
+// mmdbMap encodes the keys and values of pairs, which must be encoded
+// already, as a MaxMind DB map of less than 29 entries.
+func mmdbMap(pairs ...[]byte) []byte {
+	data := []byte{0xe0 | byte(len(pairs)/2)}
+	for _, pair := range pairs {
+		data = append(data, pair...)
+	}
+	return data
+}
+
+// namesDatabase returns a GeoIP2-City database with a single IPv4 record,
+// for 0.0.0.0/1, which has names for locales without a field in Names.
+func namesDatabase() []byte {
+	// The search tree has a single node whose left record points to the
+	// start of the data section and whose right record is empty.
+	data := []byte{0x00, 0x00, 0x11, 0x00, 0x00, 0x01}
+	data = append(data, make([]byte, 16)...)
+
+	data = append(data, mmdbMap(
+		mmdbString("city"), mmdbMap(
+			mmdbString("names"), mmdbMap(
+				mmdbString("en"), mmdbString("Seoul"),
+				mmdbString("ko"), mmdbString("서울"),
+				mmdbString("zh-TW"), mmdbString("首爾"),
+			),
+		),
+		mmdbString("country"), mmdbMap(
+			mmdbString("iso_code"), mmdbString("KR"),
+			mmdbString("names"), mmdbMap(
+				mmdbString("en"), mmdbString("South Korea"),
+				mmdbString("ko"), mmdbString("대한민국"),
+			),
+		),
+		mmdbString("subdivisions"), append([]byte{0x01, 0x04}, mmdbMap(
+			mmdbString("names"), mmdbMap(
+				mmdbString("en"), mmdbString("Seoul"),
+				mmdbString("ko"), mmdbString("서울특별시"),
+			),
+		)...),
+	)...)
+
+	data = append(data, "\xab\xcd\xefMaxMind.com"...)
+	return append(data, mmdbMap(
+		mmdbString("binary_format_major_version"), []byte{0xa1, 0x02},
+		mmdbString("binary_format_minor_version"), []byte{0xa0},
+		mmdbString("build_epoch"), []byte{0x00, 0x02},
+		mmdbString("database_type"), mmdbString("GeoIP2-City"),
+		mmdbString("description"), mmdbMap(),
+		mmdbString("ip_version"), []byte{0xa1, 0x04},
+		mmdbString("languages"), append([]byte{0x03, 0x04},
+			append(mmdbString("en"), append(mmdbString("ko"), mmdbString("zh-TW")...)...)...),
+		mmdbString("node_count"), []byte{0xc1, 0x01},
+		mmdbString("record_size"), []byte{0xa1, 0x18},
+	)...)
+}
+
+func TestNamesOther(t *testing.T) {
+	reader, err := OpenBytes(namesDatabase())
+	require.NoError(t, err)
+	defer reader.Close()
+
+	ip := netip.MustParseAddr("1.2.3.4")
+	city, err := reader.City(ip)
+	require.NoError(t, err)
+	assert.Equal(t, Names{
+		English: "Seoul",
+		Other:   NewOtherNames(map[string]string{"ko": "서울", "zh-TW": "首爾"}),
+	}, city.City.Names)
+	assert.Equal(t, Names{
+		English: "South Korea",
+		Other:   NewOtherNames(map[string]string{"ko": "대한민국"}),
+	}, city.Country.Names)
+	require.Len(t, city.Subdivisions, 1)
+	assert.Equal(t, "서울특별시", city.Subdivisions[0].Names.Get("ko"))
+	assert.False(t, city.Continent.Names.HasData())
+
+	names := city.City.Names
+	assert.True(t, Names{Other: NewOtherNames(map[string]string{"ko": "서울"})}.HasData())
+	assert.False(t, Names{Other: NewOtherNames(nil)}.HasData())
+	assert.False(t, Names{}.HasData())
+	assert.Equal(t, "서울", names.Get("ko"))
+	assert.Equal(t, "首爾", names.Get("ZH-tw"))
+	assert.Equal(t, "서울", names.Best("ko-KR", "en"))
+	assert.Equal(t, "首爾", names.Best("zh-Hant", "en"))
+
+	var into City
+	require.NoError(t, reader.CityInto(ip, &into))
+	assert.Equal(t, city.City.Names, into.City.Names)
+
+	country, err := reader.Country(ip)
+	require.NoError(t, err)
+	assert.Equal(t, city.Country.Names, country.Country.Names)
+
+	record, err := Lookup[City](reader, ip, MethodCity)
+	require.NoError(t, err)
+	assert.Equal(t, city.City.Names, record.City.Names)
+}
+
+func TestOtherNames(t *testing.T) {
+	source := map[string]string{"zh-TW": "首爾", "ko": "서울"}
+	other := NewOtherNames(source)
+	source["ko"] = "changed"
+	assert.Equal(t, 2, other.Len())
+	assert.Equal(t, "서울", other.Get("KO"))
+	var locales []string
+	for locale := range other.All() {
+		locales = append(locales, locale)
+	}
+	assert.Equal(t, []string{"ko", "zh-TW"}, locales)
+
+	var none *OtherNames
+	assert.Zero(t, none.Len())
+	assert.Empty(t, none.Get("ko"))
+	assert.Nil(t, NewOtherNames(map[string]string{}))
+
+	// Names remain comparable, and setting a name does not modify the
+	// OtherNames of a copy.
+	names := Names{English: "Seoul", Other: other}
+	names2 := names
+	assert.True(t, names == names2) //nolint:testifylint // testing ==
+	assert.True(t, map[Names]bool{names: true}[names2])
+	names2.set("ja-JP", "ソウル")
+	assert.Equal(t, 2, names.Other.Len())
+	assert.Equal(t, "ソウル", names2.Get("ja-JP"))
+	assert.False(t, names == names2) //nolint:testifylint // testing ==
+}
+
+func TestCityAllocations(t *testing.T) {
+	// The names for locales without a field in Names must not make decoding
+	// the other fields more expensive. The test database lists "zh" in its
+	// metadata, so its names are looked up as well.
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	ip := netip.MustParseAddr("81.2.69.160")
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = reader.City(ip)
+	})
+	assert.LessOrEqual(t, allocs, 9.0)
+}
+
+func TestNamesJSON(t *testing.T) {
+	names := Names{
+		English: "Seoul",
+		Other:   NewOtherNames(map[string]string{"ko": "서울", "zh-TW": "首爾"}),
+	}
+
+	data, err := json.Marshal(names)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"en":"Seoul","ko":"서울","zh-TW":"首爾"}`, string(data))
+
+	var decoded Names
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, names, decoded)
+
+	data, err = json.Marshal(Names{English: "London", German: "London"})
+	require.NoError(t, err)
+	assert.Equal(t, `{"de":"London","en":"London"}`, string(data))
+
+	decoded = Names{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, Names{English: "London", German: "London"}, decoded)
+
+	data, err = json.Marshal(CityRecord{GeoNameID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, `{"geoname_id":1}`, string(data))
+}
@@ -0,0 +1,223 @@
+package geoip2
+
+import (
+	"iter"
+	"net/netip"
+	"reflect"
+
+	"github.com/oschwald/maxminddb-golang/v2"
+)
+
+// Networks returns an iterator over the networks in the database, decoding
+// the record for each of them into a new value of type T, as Lookup does. The
+// Network field of each value, if it has one, is set to the network of the
+// record. As no lookup is performed, the IPAddress field is left unset.
+//
+// The iteration is only allowed on the databases supported by the Reader
+// method identified by method. Otherwise, an InvalidMethodError is yielded.
+//
+// The options are passed on to the maxminddb Networks method. By default,
+// networks without data and the aliases of the IPv4 subtree in IPv6
+// databases, e.g., ::ffff:0:0/96, are skipped. Use
+// maxminddb.IncludeAliasedNetworks and maxminddb.IncludeNetworksWithoutData
+// to include them, or maxminddb.SkipEmptyValues to also skip networks whose
+// record is empty.
+//
+// If a record cannot be decoded, the error is yielded along with the
+// partially decoded value. The iteration continues if the loop body does not
+// break.
+func Networks[T any](
+	r *Reader,
+	method Method,
+	options ...maxminddb.NetworksOption,
+) iter.Seq2[*T, error] {
+	return decodeNetworks[T](r, method, func() iter.Seq[maxminddb.Result] {
+		return r.mmdbReader.Networks(options...)
+	})
+}
+
+// NetworksWithin returns an iterator over the networks in the database that
+// are contained within prefix, decoding the record for each of them into a
+// new value of type T. If prefix is itself contained within a network in the
+// database, that network is the only one yielded. Otherwise, it behaves as
+// Networks does.
+func NetworksWithin[T any](
+	r *Reader,
+	prefix netip.Prefix,
+	method Method,
+	options ...maxminddb.NetworksOption,
+) iter.Seq2[*T, error] {
+	return decodeNetworks[T](r, method, func() iter.Seq[maxminddb.Result] {
+		return r.mmdbReader.NetworksWithin(prefix, options...)
+	})
+}
+
+func decodeNetworks[T any](
+	r *Reader,
+	method Method,
+	results func() iter.Seq[maxminddb.Result],
+) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		if databaseType(method)&r.databaseType == 0 {
+			yield(nil, InvalidMethodError{method.String(), r.Metadata().DatabaseType})
+			return
+		}
+		for result := range results() {
+			var val T
+			err := r.decode(result, &val)
+			if err == nil {
+				setNetworkFields(reflect.ValueOf(&val).Elem(), netip.Addr{}, result.Prefix())
+			}
+			if !yield(&val, err) {
+				return
+			}
+		}
+	}
+}
+
+// EnterpriseNetworks returns an iterator over the networks in the database
+// and their Enterprise records. See Networks for details.
+func (r *Reader) EnterpriseNetworks(
+	options ...maxminddb.NetworksOption,
+) iter.Seq2[*Enterprise, error] {
+	return Networks[Enterprise](r, MethodEnterprise, options...)
+}
+
+// CityNetworks returns an iterator over the networks in the database and
+// their City records. See Networks for details.
+func (r *Reader) CityNetworks(options ...maxminddb.NetworksOption) iter.Seq2[*City, error] {
+	return Networks[City](r, MethodCity, options...)
+}
+
+// CountryNetworks returns an iterator over the networks in the database and
+// their Country records. See Networks for details.
+func (r *Reader) CountryNetworks(options ...maxminddb.NetworksOption) iter.Seq2[*Country, error] {
+	return Networks[Country](r, MethodCountry, options...)
+}
+
+// AnonymousIPNetworks returns an iterator over the networks in the database
+// and their AnonymousIP records. See Networks for details.
+func (r *Reader) AnonymousIPNetworks(
+	options ...maxminddb.NetworksOption,
+) iter.Seq2[*AnonymousIP, error] {
+	return Networks[AnonymousIP](r, MethodAnonymousIP, options...)
+}
+
+// AnonymousPlusNetworks returns an iterator over the networks in the
+// database and their AnonymousPlus records. See Networks for details.
+func (r *Reader) AnonymousPlusNetworks(
+	options ...maxminddb.NetworksOption,
+) iter.Seq2[*AnonymousPlus, error] {
+	return Networks[AnonymousPlus](r, MethodAnonymousPlus, options...)
+}
+
+// ASNNetworks returns an iterator over the networks in the database and
+// their ASN records. See Networks for details.
+func (r *Reader) ASNNetworks(options ...maxminddb.NetworksOption) iter.Seq2[*ASN, error] {
+	return Networks[ASN](r, MethodASN, options...)
+}
+
+// ConnectionTypeNetworks returns an iterator over the networks in the
+// database and their ConnectionType records. See Networks for details.
+func (r *Reader) ConnectionTypeNetworks(
+	options ...maxminddb.NetworksOption,
+) iter.Seq2[*ConnectionType, error] {
+	return Networks[ConnectionType](r, MethodConnectionType, options...)
+}
+
+// DomainNetworks returns an iterator over the networks in the database and
+// their Domain records. See Networks for details.
+func (r *Reader) DomainNetworks(options ...maxminddb.NetworksOption) iter.Seq2[*Domain, error] {
+	return Networks[Domain](r, MethodDomain, options...)
+}
+
+// ISPNetworks returns an iterator over the networks in the database and
+// their ISP records. See Networks for details.
+func (r *Reader) ISPNetworks(options ...maxminddb.NetworksOption) iter.Seq2[*ISP, error] {
+	return Networks[ISP](r, MethodISP, options...)
+}
+
+// EnterpriseNetworksWithin returns an iterator over the networks in the
+// database within prefix and their Enterprise records. See NetworksWithin for
+// details.
+func (r *Reader) EnterpriseNetworksWithin(
+	prefix netip.Prefix,
+	options ...maxminddb.NetworksOption,
+) iter.Seq2[*Enterprise, error] {
+	return NetworksWithin[Enterprise](r, prefix, MethodEnterprise, options...)
+}
+
+// CityNetworksWithin returns an iterator over the networks in the database
+// within prefix and their City records. See NetworksWithin for details.
+func (r *Reader) CityNetworksWithin(
+	prefix netip.Prefix,
+	options ...maxminddb.NetworksOption,
+) iter.Seq2[*City, error] {
+	return NetworksWithin[City](r, prefix, MethodCity, options...)
+}
+
+// CountryNetworksWithin returns an iterator over the networks in the database
+// within prefix and their Country records. See NetworksWithin for details.
+func (r *Reader) CountryNetworksWithin(
+	prefix netip.Prefix,
+	options ...maxminddb.NetworksOption,
+) iter.Seq2[*Country, error] {
+	return NetworksWithin[Country](r, prefix, MethodCountry, options...)
+}
+
+// AnonymousIPNetworksWithin returns an iterator over the networks in the
+// database within prefix and their AnonymousIP records. See NetworksWithin
+// for details.
+func (r *Reader) AnonymousIPNetworksWithin(
+	prefix netip.Prefix,
+	options ...maxminddb.NetworksOption,
+) iter.Seq2[*AnonymousIP, error] {
+	return NetworksWithin[AnonymousIP](r, prefix, MethodAnonymousIP, options...)
+}
+
+// AnonymousPlusNetworksWithin returns an iterator over the networks in the
+// database within prefix and their AnonymousPlus records. See NetworksWithin
+// for details.
+func (r *Reader) AnonymousPlusNetworksWithin(
+	prefix netip.Prefix,
+	options ...maxminddb.NetworksOption,
+) iter.Seq2[*AnonymousPlus, error] {
+	return NetworksWithin[AnonymousPlus](r, prefix, MethodAnonymousPlus, options...)
+}
+
+// ASNNetworksWithin returns an iterator over the networks in the database
+// within prefix and their ASN records. See NetworksWithin for details.
+func (r *Reader) ASNNetworksWithin(
+	prefix netip.Prefix,
+	options ...maxminddb.NetworksOption,
+) iter.Seq2[*ASN, error] {
+	return NetworksWithin[ASN](r, prefix, MethodASN, options...)
+}
+
+// ConnectionTypeNetworksWithin returns an iterator over the networks in the
+// database within prefix and their ConnectionType records. See NetworksWithin
+// for details.
+func (r *Reader) ConnectionTypeNetworksWithin(
+	prefix netip.Prefix,
+	options ...maxminddb.NetworksOption,
+) iter.Seq2[*ConnectionType, error] {
+	return NetworksWithin[ConnectionType](r, prefix, MethodConnectionType, options...)
+}
+
+// DomainNetworksWithin returns an iterator over the networks in the database
+// within prefix and their Domain records. See NetworksWithin for details.
+func (r *Reader) DomainNetworksWithin(
+	prefix netip.Prefix,
+	options ...maxminddb.NetworksOption,
+) iter.Seq2[*Domain, error] {
+	return NetworksWithin[Domain](r, prefix, MethodDomain, options...)
+}
+
+// ISPNetworksWithin returns an iterator over the networks in the database
+// within prefix and their ISP records. See NetworksWithin for details.
+func (r *Reader) ISPNetworksWithin(
+	prefix netip.Prefix,
+	options ...maxminddb.NetworksOption,
+) iter.Seq2[*ISP, error] {
+	return NetworksWithin[ISP](r, prefix, MethodISP, options...)
+}
@@ -0,0 +1,141 @@
+package geoip2
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/oschwald/maxminddb-golang/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestASNNetworks(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoLite2-ASN-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	testAddr := netip.MustParseAddr("1.128.0.0")
+	aliased := netip.MustParsePrefix("::ffff:0:0/96")
+
+	var found bool
+	count := 0
+	for record, err := range reader.ASNNetworks() {
+		require.NoError(t, err)
+		count++
+
+		assert.True(t, record.Network.IsValid())
+		assert.False(t, record.IPAddress.IsValid())
+		assert.True(t, record.HasData())
+		assert.False(t, aliased.Overlaps(record.Network))
+
+		if record.Network.Contains(testAddr) {
+			found = true
+			assert.Equal(t, uint(1221), record.AutonomousSystemNumber)
+			assert.Equal(t, "Telstra Pty Ltd", record.AutonomousSystemOrganization)
+		}
+	}
+	assert.True(t, found)
+
+	aliasedCount := 0
+	for _, err := range reader.ASNNetworks(maxminddb.IncludeAliasedNetworks()) {
+		require.NoError(t, err)
+		aliasedCount++
+	}
+	assert.Greater(t, aliasedCount, count)
+}
+
+func TestCityNetworks(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	testAddr := netip.MustParseAddr("81.2.69.160")
+
+	var found bool
+	for record, err := range reader.CityNetworks() {
+		require.NoError(t, err)
+		assert.True(t, record.Traits.Network.IsValid())
+
+		if record.Traits.Network.Contains(testAddr) {
+			found = true
+			expected, err := reader.City(testAddr)
+			require.NoError(t, err)
+			expected.Traits.IPAddress = netip.Addr{}
+			assert.Equal(t, expected, record)
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestNetworksCustomStruct(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-Country-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	count := 0
+	for record, err := range Networks[countryAndAnycast](reader, MethodCountry) {
+		require.NoError(t, err)
+		assert.True(t, record.Traits.Network.IsValid())
+		count++
+	}
+	assert.Positive(t, count)
+}
+
+func TestNetworksInvalidMethod(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	count := 0
+	for record, err := range reader.ASNNetworks() {
+		count++
+		assert.Nil(t, record)
+		require.ErrorAs(t, err, &InvalidMethodError{})
+	}
+	assert.Equal(t, 1, count)
+}
+
+func TestCityNetworksWithin(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	prefix := netip.MustParsePrefix("81.2.69.0/24")
+	testAddr := netip.MustParseAddr("81.2.69.160")
+
+	var found bool
+	for record, err := range reader.CityNetworksWithin(prefix) {
+		require.NoError(t, err)
+		assert.True(t, prefix.Overlaps(record.Traits.Network))
+		assert.LessOrEqual(t, prefix.Bits(), record.Traits.Network.Bits())
+
+		if record.Traits.Network.Contains(testAddr) {
+			found = true
+			assert.Equal(t, "London", record.City.Names.English)
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestASNNetworksWithinContainingNetwork(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoLite2-ASN-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	prefix := netip.MustParsePrefix("1.128.0.0/24")
+
+	var records []*ASN
+	for record, err := range reader.ASNNetworksWithin(prefix) {
+		require.NoError(t, err)
+		records = append(records, record)
+	}
+	require.Len(t, records, 1)
+	assert.True(t, records[0].Network.Contains(prefix.Addr()))
+	assert.Less(t, records[0].Network.Bits(), prefix.Bits())
+	assert.Equal(t, uint(1221), records[0].AutonomousSystemNumber)
+
+	for record, err := range reader.CityNetworksWithin(prefix) {
+		assert.Nil(t, record)
+		require.ErrorAs(t, err, &InvalidMethodError{})
+	}
+}
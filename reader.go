@@ -34,6 +34,7 @@
 //   - Country: Country-level geolocation
 //   - ASN: Autonomous system information
 //   - AnonymousIP: Anonymous network detection
+//   - AnonymousPlus: Anonymous network detection with provider details
 //   - Enterprise: Enhanced City data with additional fields
 //   - ISP: Internet service provider information
 //   - Domain: Second-level domain data
@@ -53,7 +54,10 @@ package geoip2
 
 import (
 	"fmt"
+	"io"
+	"io/fs"
 	"net/netip"
+	"os"
 
 	"github.com/oschwald/maxminddb-golang/v2"
 )
@@ -69,13 +73,66 @@ const (
 	isDomain
 	isEnterprise
 	isISP
+	isAnonymousPlus
 )
 
 // Reader holds the maxminddb.Reader struct. It can be created using the
 // Open and OpenBytes functions.
 type Reader struct {
 	mmdbReader   *maxminddb.Reader
+	locales      []string
 	databaseType databaseType
+	strict       bool
+	// otherLocales are the languages of the metadata without a field in
+	// Names. See decodeOtherNames.
+	otherLocales []otherLocale
+}
+
+type readerOptions struct {
+	locales []string
+	strict  bool
+}
+
+// ReaderOption configures the Reader returned by Open and the other functions
+// opening a database.
+type ReaderOption func(*readerOptions)
+
+// StrictLookups causes the lookup methods of the Reader to return an error,
+// rather than an empty struct, when the database has no record for an IP
+// address. If the IP address belongs to a special-purpose range, e.g., a
+// private network, a ReservedAddressError is returned. Otherwise, an
+// AddressNotFoundError is returned. This allows telling internal addresses
+// apart from those that are simply unknown to the database.
+func StrictLookups() ReaderOption {
+	return func(o *readerOptions) {
+		o.strict = true
+	}
+}
+
+// Locales sets the BCP 47 language tags, in order of preference, used by the
+// LocalizedName method of the Reader, e.g., "pt-BR", "es", "en". They are
+// followed by English and the other languages of the database, as listed in
+// its metadata, so that a name is found whenever one is available. By
+// default, only the latter are used.
+func Locales(locales ...string) ReaderOption {
+	return func(o *readerOptions) {
+		o.locales = locales
+	}
+}
+
+func newReader(reader *maxminddb.Reader, options []ReaderOption) (*Reader, error) {
+	var opts readerOptions
+	for _, option := range options {
+		option(&opts)
+	}
+	dbType, err := getDBType(reader)
+	return &Reader{
+		mmdbReader:   reader,
+		locales:      readerLocales(opts.locales, reader.Metadata.Languages),
+		databaseType: dbType,
+		strict:       opts.strict,
+		otherLocales: otherLocales(reader.Metadata.Languages),
+	}, err
 }
 
 // InvalidMethodError is returned when a lookup method is called on a
@@ -91,6 +148,20 @@ func (e InvalidMethodError) Error() string {
 		e.Method, e.DatabaseType)
 }
 
+// AddressNotFoundError is returned by the lookup methods of a Reader opened
+// with StrictLookups when the database has no record for an IP address.
+type AddressNotFoundError struct {
+	// IPAddress is the IP address looked up.
+	IPAddress netip.Addr
+	// Network is the largest network containing IPAddress for which the
+	// database has no record.
+	Network netip.Prefix
+}
+
+func (e AddressNotFoundError) Error() string {
+	return fmt.Sprintf("geoip2: the address %s is not in the database", e.IPAddress)
+}
+
 // UnknownDatabaseTypeError is returned when an unknown database type is
 // opened.
 type UnknownDatabaseTypeError struct {
@@ -105,26 +176,52 @@ func (e UnknownDatabaseTypeError) Error() string {
 // Open takes a string path to a file and returns a Reader struct or an error.
 // The database file is opened using a memory map. Use the Close method on the
 // Reader object to return the resources to the system.
-func Open(file string) (*Reader, error) {
+func Open(file string, options ...ReaderOption) (*Reader, error) {
 	reader, err := maxminddb.Open(file)
 	if err != nil {
 		return nil, err
 	}
-	dbType, err := getDBType(reader)
-	return &Reader{reader, dbType}, err
+	return newReader(reader, options)
 }
 
 // OpenBytes takes a byte slice corresponding to a GeoIP2/GeoLite2 database
 // file and returns a Reader struct or an error. Note that the byte slice is
 // used directly; any modification of it after opening the database will result
 // in errors while reading from the database.
-func OpenBytes(bytes []byte) (*Reader, error) {
+func OpenBytes(bytes []byte, options ...ReaderOption) (*Reader, error) {
 	reader, err := maxminddb.OpenBytes(bytes)
 	if err != nil {
 		return nil, err
 	}
-	dbType, err := getDBType(reader)
-	return &Reader{reader, dbType}, err
+	return newReader(reader, options)
+}
+
+// OpenFS takes an fs.FS and the name of a GeoIP2/GeoLite2 database file
+// within it and returns a Reader struct or an error. If the file is backed by
+// the operating system, e.g., when fsys was created with os.DirFS, it is
+// opened using Open and memory mapped. Otherwise, e.g., for an embed.FS or a
+// fstest.MapFS, the file is read into memory and opened using OpenBytes. Use
+// the Close method on the Reader object to return the resources to the
+// system.
+func OpenFS(fsys fs.FS, name string, options ...ReaderOption) (*Reader, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if osFile, ok := f.(*os.File); ok {
+		file := osFile.Name()
+		if err := osFile.Close(); err != nil {
+			return nil, err
+		}
+		return Open(file, options...)
+	}
+	defer f.Close() //nolint:errcheck // error is not relevant for a read-only file
+
+	bytes, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return OpenBytes(bytes, options...)
 }
 
 // FromBytes takes a byte slice corresponding to a GeoIP2/GeoLite2 database
@@ -134,22 +231,33 @@ func OpenBytes(bytes []byte) (*Reader, error) {
 //
 // Deprecated: Use OpenBytes instead. FromBytes will be removed in a future
 // version.
-func FromBytes(bytes []byte) (*Reader, error) {
-	return OpenBytes(bytes)
+func FromBytes(bytes []byte, options ...ReaderOption) (*Reader, error) {
+	return OpenBytes(bytes, options...)
 }
 
 func getDBType(reader *maxminddb.Reader) (databaseType, error) {
-	switch reader.Metadata.DatabaseType {
+	dbType, ok := resolveDatabaseType(reader.Metadata.DatabaseType)
+	if !ok {
+		return 0, UnknownDatabaseTypeError{reader.Metadata.DatabaseType}
+	}
+	return dbType, nil
+}
+
+// builtinDatabaseType returns the lookup methods supported by the database
+// types known to this package.
+func builtinDatabaseType(name string) (databaseType, bool) {
+	switch name {
 	case "GeoIP2-Anonymous-IP":
-		return isAnonymousIP, nil
-	case "DBIP-ASN-Lite (compat=GeoLite2-ASN)",
-		"GeoLite2-ASN":
-		return isASN, nil
+		return isAnonymousIP, true
+	// The Anonymous Plus database contains all of the Anonymous IP data.
+	case "GeoIP-Anonymous-Plus":
+		return isAnonymousPlus | isAnonymousIP, true
+	case "GeoLite2-ASN":
+		return isASN, true
 	// We allow City lookups on Country for back compat
 	case "DBIP-City-Lite",
 		"DBIP-Country-Lite",
 		"DBIP-Country",
-		"DBIP-Location (compat=City)",
 		"GeoLite2-City",
 		"GeoIP-City-Redacted-US",
 		"GeoIP2-City",
@@ -161,21 +269,40 @@ func getDBType(reader *maxminddb.Reader) (databaseType, error) {
 		"GeoIP2-Precision-City",
 		"GeoLite2-Country",
 		"GeoIP2-Country":
-		return isCity | isCountry, nil
+		return isCity | isCountry, true
 	case "GeoIP2-Connection-Type":
-		return isConnectionType, nil
+		return isConnectionType, true
 	case "GeoIP2-Domain":
-		return isDomain, nil
-	case "DBIP-ISP (compat=Enterprise)",
-		"DBIP-Location-ISP (compat=Enterprise)",
-		"GeoIP-Enterprise-Redacted-US",
+		return isDomain, true
+	case "GeoIP-Enterprise-Redacted-US",
 		"GeoIP2-Enterprise":
-		return isEnterprise | isCity | isCountry, nil
+		return isEnterprise | isCity | isCountry, true
 	case "GeoIP2-ISP", "GeoIP2-Precision-ISP":
-		return isISP | isASN, nil
+		return isISP | isASN, true
 	default:
-		return 0, UnknownDatabaseTypeError{reader.Metadata.DatabaseType}
+		return 0, false
+	}
+}
+
+// checkFound returns an error if the Reader was opened with StrictLookups and
+// result has no record.
+func (r *Reader) checkFound(ipAddress netip.Addr, result maxminddb.Result) error {
+	if !r.strict || result.Err() != nil || result.Found() {
+		return nil
+	}
+	if reserved, ok := LookupReserved(ipAddress); ok {
+		return ReservedAddressError{IPAddress: ipAddress, Range: reserved}
+	}
+	return AddressNotFoundError{IPAddress: ipAddress, Network: result.Prefix()}
+}
+
+// decode decodes the record of result into v, as result.Decode does, and then
+// the names for the locales without a field in Names. See decodeOtherNames.
+func (r *Reader) decode(result maxminddb.Result, v any) error {
+	if err := result.Decode(v); err != nil {
+		return err
 	}
+	return r.decodeOtherNames(result, v)
 }
 
 // Enterprise takes an IP address as a netip.Addr and returns an Enterprise
@@ -186,8 +313,11 @@ func (r *Reader) Enterprise(ipAddress netip.Addr) (*Enterprise, error) {
 		return nil, InvalidMethodError{"Enterprise", r.Metadata().DatabaseType}
 	}
 	result := r.mmdbReader.Lookup(ipAddress)
+	if err := r.checkFound(ipAddress, result); err != nil {
+		return nil, err
+	}
 	var enterprise Enterprise
-	err := result.Decode(&enterprise)
+	err := r.decode(result, &enterprise)
 	if err != nil {
 		return &enterprise, err
 	}
@@ -196,6 +326,29 @@ func (r *Reader) Enterprise(ipAddress netip.Addr) (*Enterprise, error) {
 	return &enterprise, nil
 }
 
+// EnterpriseInto is like Enterprise, but it decodes the record into the
+// provided Enterprise struct, rather than allocating a new one. Any data in
+// enterprise from a previous lookup is overwritten.
+func (r *Reader) EnterpriseInto(ipAddress netip.Addr, enterprise *Enterprise) error {
+	if isEnterprise&r.databaseType == 0 {
+		return InvalidMethodError{"Enterprise", r.Metadata().DatabaseType}
+	}
+	result := r.mmdbReader.Lookup(ipAddress)
+	*enterprise = Enterprise{}
+	if err := r.checkFound(ipAddress, result); err != nil {
+		return err
+	}
+	enterprise.Location.allocateCoordinates()
+	err := r.decode(result, enterprise)
+	enterprise.Location.clearMissingCoordinates()
+	if err != nil {
+		return err
+	}
+	enterprise.Traits.IPAddress = ipAddress
+	enterprise.Traits.Network = result.Prefix()
+	return nil
+}
+
 // City takes an IP address as a netip.Addr and returns a City struct
 // and/or an error. Although this can be used with other databases, this
 // method generally should be used with the GeoIP2 or GeoLite2 City databases.
@@ -204,8 +357,11 @@ func (r *Reader) City(ipAddress netip.Addr) (*City, error) {
 		return nil, InvalidMethodError{"City", r.Metadata().DatabaseType}
 	}
 	result := r.mmdbReader.Lookup(ipAddress)
+	if err := r.checkFound(ipAddress, result); err != nil {
+		return nil, err
+	}
 	var city City
-	err := result.Decode(&city)
+	err := r.decode(result, &city)
 	if err != nil {
 		return &city, err
 	}
@@ -214,6 +370,29 @@ func (r *Reader) City(ipAddress netip.Addr) (*City, error) {
 	return &city, nil
 }
 
+// CityInto is like City, but it decodes the record into the provided City
+// struct, rather than allocating a new one. Any data in city from a previous
+// lookup is overwritten.
+func (r *Reader) CityInto(ipAddress netip.Addr, city *City) error {
+	if isCity&r.databaseType == 0 {
+		return InvalidMethodError{"City", r.Metadata().DatabaseType}
+	}
+	result := r.mmdbReader.Lookup(ipAddress)
+	*city = City{}
+	if err := r.checkFound(ipAddress, result); err != nil {
+		return err
+	}
+	city.Location.allocateCoordinates()
+	err := r.decode(result, city)
+	city.Location.clearMissingCoordinates()
+	if err != nil {
+		return err
+	}
+	city.Traits.IPAddress = ipAddress
+	city.Traits.Network = result.Prefix()
+	return nil
+}
+
 // Country takes an IP address as a netip.Addr and returns a Country struct
 // and/or an error. Although this can be used with other databases, this
 // method generally should be used with the GeoIP2 or GeoLite2 Country
@@ -223,8 +402,11 @@ func (r *Reader) Country(ipAddress netip.Addr) (*Country, error) {
 		return nil, InvalidMethodError{"Country", r.Metadata().DatabaseType}
 	}
 	result := r.mmdbReader.Lookup(ipAddress)
+	if err := r.checkFound(ipAddress, result); err != nil {
+		return nil, err
+	}
 	var country Country
-	err := result.Decode(&country)
+	err := r.decode(result, &country)
 	if err != nil {
 		return &country, err
 	}
@@ -233,6 +415,27 @@ func (r *Reader) Country(ipAddress netip.Addr) (*Country, error) {
 	return &country, nil
 }
 
+// CountryInto is like Country, but it decodes the record into the provided
+// Country struct, rather than allocating a new one. Any data in country from
+// a previous lookup is overwritten.
+func (r *Reader) CountryInto(ipAddress netip.Addr, country *Country) error {
+	if isCountry&r.databaseType == 0 {
+		return InvalidMethodError{"Country", r.Metadata().DatabaseType}
+	}
+	result := r.mmdbReader.Lookup(ipAddress)
+	*country = Country{}
+	if err := r.checkFound(ipAddress, result); err != nil {
+		return err
+	}
+	err := r.decode(result, country)
+	if err != nil {
+		return err
+	}
+	country.Traits.IPAddress = ipAddress
+	country.Traits.Network = result.Prefix()
+	return nil
+}
+
 // AnonymousIP takes an IP address as a netip.Addr and returns a
 // AnonymousIP struct and/or an error.
 func (r *Reader) AnonymousIP(ipAddress netip.Addr) (*AnonymousIP, error) {
@@ -240,6 +443,9 @@ func (r *Reader) AnonymousIP(ipAddress netip.Addr) (*AnonymousIP, error) {
 		return nil, InvalidMethodError{"AnonymousIP", r.Metadata().DatabaseType}
 	}
 	result := r.mmdbReader.Lookup(ipAddress)
+	if err := r.checkFound(ipAddress, result); err != nil {
+		return nil, err
+	}
 	var anonIP AnonymousIP
 	err := result.Decode(&anonIP)
 	if err != nil {
@@ -250,6 +456,69 @@ func (r *Reader) AnonymousIP(ipAddress netip.Addr) (*AnonymousIP, error) {
 	return &anonIP, nil
 }
 
+// AnonymousIPInto is like AnonymousIP, but it decodes the record into the
+// provided AnonymousIP struct, rather than allocating a new one. Any data in
+// anonIP from a previous lookup is overwritten.
+func (r *Reader) AnonymousIPInto(ipAddress netip.Addr, anonIP *AnonymousIP) error {
+	if isAnonymousIP&r.databaseType == 0 {
+		return InvalidMethodError{"AnonymousIP", r.Metadata().DatabaseType}
+	}
+	result := r.mmdbReader.Lookup(ipAddress)
+	*anonIP = AnonymousIP{}
+	if err := r.checkFound(ipAddress, result); err != nil {
+		return err
+	}
+	err := result.Decode(anonIP)
+	if err != nil {
+		return err
+	}
+	anonIP.IPAddress = ipAddress
+	anonIP.Network = result.Prefix()
+	return nil
+}
+
+// AnonymousPlus takes an IP address as a netip.Addr and returns a
+// AnonymousPlus struct and/or an error. This is intended to be used with the
+// GeoIP Anonymous Plus database.
+func (r *Reader) AnonymousPlus(ipAddress netip.Addr) (*AnonymousPlus, error) {
+	if isAnonymousPlus&r.databaseType == 0 {
+		return nil, InvalidMethodError{"AnonymousPlus", r.Metadata().DatabaseType}
+	}
+	result := r.mmdbReader.Lookup(ipAddress)
+	if err := r.checkFound(ipAddress, result); err != nil {
+		return nil, err
+	}
+	var anonPlus AnonymousPlus
+	err := result.Decode(&anonPlus)
+	if err != nil {
+		return &anonPlus, err
+	}
+	anonPlus.IPAddress = ipAddress
+	anonPlus.Network = result.Prefix()
+	return &anonPlus, nil
+}
+
+// AnonymousPlusInto is like AnonymousPlus, but it decodes the record into
+// the provided AnonymousPlus struct, rather than allocating a new one. Any
+// data in anonPlus from a previous lookup is overwritten.
+func (r *Reader) AnonymousPlusInto(ipAddress netip.Addr, anonPlus *AnonymousPlus) error {
+	if isAnonymousPlus&r.databaseType == 0 {
+		return InvalidMethodError{"AnonymousPlus", r.Metadata().DatabaseType}
+	}
+	result := r.mmdbReader.Lookup(ipAddress)
+	*anonPlus = AnonymousPlus{}
+	if err := r.checkFound(ipAddress, result); err != nil {
+		return err
+	}
+	err := result.Decode(anonPlus)
+	if err != nil {
+		return err
+	}
+	anonPlus.IPAddress = ipAddress
+	anonPlus.Network = result.Prefix()
+	return nil
+}
+
 // ASN takes an IP address as a netip.Addr and returns a ASN struct and/or
 // an error.
 func (r *Reader) ASN(ipAddress netip.Addr) (*ASN, error) {
@@ -257,6 +526,9 @@ func (r *Reader) ASN(ipAddress netip.Addr) (*ASN, error) {
 		return nil, InvalidMethodError{"ASN", r.Metadata().DatabaseType}
 	}
 	result := r.mmdbReader.Lookup(ipAddress)
+	if err := r.checkFound(ipAddress, result); err != nil {
+		return nil, err
+	}
 	var val ASN
 	err := result.Decode(&val)
 	if err != nil {
@@ -267,6 +539,27 @@ func (r *Reader) ASN(ipAddress netip.Addr) (*ASN, error) {
 	return &val, nil
 }
 
+// ASNInto is like ASN, but it decodes the record into the provided ASN
+// struct, rather than allocating a new one. Any data in asn from a previous
+// lookup is overwritten.
+func (r *Reader) ASNInto(ipAddress netip.Addr, asn *ASN) error {
+	if isASN&r.databaseType == 0 {
+		return InvalidMethodError{"ASN", r.Metadata().DatabaseType}
+	}
+	result := r.mmdbReader.Lookup(ipAddress)
+	*asn = ASN{}
+	if err := r.checkFound(ipAddress, result); err != nil {
+		return err
+	}
+	err := result.Decode(asn)
+	if err != nil {
+		return err
+	}
+	asn.IPAddress = ipAddress
+	asn.Network = result.Prefix()
+	return nil
+}
+
 // ConnectionType takes an IP address as a netip.Addr and returns a
 // ConnectionType struct and/or an error.
 func (r *Reader) ConnectionType(ipAddress netip.Addr) (*ConnectionType, error) {
@@ -274,6 +567,9 @@ func (r *Reader) ConnectionType(ipAddress netip.Addr) (*ConnectionType, error) {
 		return nil, InvalidMethodError{"ConnectionType", r.Metadata().DatabaseType}
 	}
 	result := r.mmdbReader.Lookup(ipAddress)
+	if err := r.checkFound(ipAddress, result); err != nil {
+		return nil, err
+	}
 	var val ConnectionType
 	err := result.Decode(&val)
 	if err != nil {
@@ -284,6 +580,27 @@ func (r *Reader) ConnectionType(ipAddress netip.Addr) (*ConnectionType, error) {
 	return &val, nil
 }
 
+// ConnectionTypeInto is like ConnectionType, but it decodes the record into
+// the provided ConnectionType struct, rather than allocating a new one. Any
+// data in connType from a previous lookup is overwritten.
+func (r *Reader) ConnectionTypeInto(ipAddress netip.Addr, connType *ConnectionType) error {
+	if isConnectionType&r.databaseType == 0 {
+		return InvalidMethodError{"ConnectionType", r.Metadata().DatabaseType}
+	}
+	result := r.mmdbReader.Lookup(ipAddress)
+	*connType = ConnectionType{}
+	if err := r.checkFound(ipAddress, result); err != nil {
+		return err
+	}
+	err := result.Decode(connType)
+	if err != nil {
+		return err
+	}
+	connType.IPAddress = ipAddress
+	connType.Network = result.Prefix()
+	return nil
+}
+
 // Domain takes an IP address as a netip.Addr and returns a
 // Domain struct and/or an error.
 func (r *Reader) Domain(ipAddress netip.Addr) (*Domain, error) {
@@ -291,6 +608,9 @@ func (r *Reader) Domain(ipAddress netip.Addr) (*Domain, error) {
 		return nil, InvalidMethodError{"Domain", r.Metadata().DatabaseType}
 	}
 	result := r.mmdbReader.Lookup(ipAddress)
+	if err := r.checkFound(ipAddress, result); err != nil {
+		return nil, err
+	}
 	var val Domain
 	err := result.Decode(&val)
 	if err != nil {
@@ -301,6 +621,27 @@ func (r *Reader) Domain(ipAddress netip.Addr) (*Domain, error) {
 	return &val, nil
 }
 
+// DomainInto is like Domain, but it decodes the record into the provided
+// Domain struct, rather than allocating a new one. Any data in domain from a
+// previous lookup is overwritten.
+func (r *Reader) DomainInto(ipAddress netip.Addr, domain *Domain) error {
+	if isDomain&r.databaseType == 0 {
+		return InvalidMethodError{"Domain", r.Metadata().DatabaseType}
+	}
+	result := r.mmdbReader.Lookup(ipAddress)
+	*domain = Domain{}
+	if err := r.checkFound(ipAddress, result); err != nil {
+		return err
+	}
+	err := result.Decode(domain)
+	if err != nil {
+		return err
+	}
+	domain.IPAddress = ipAddress
+	domain.Network = result.Prefix()
+	return nil
+}
+
 // ISP takes an IP address as a netip.Addr and returns a ISP struct and/or
 // an error.
 func (r *Reader) ISP(ipAddress netip.Addr) (*ISP, error) {
@@ -308,6 +649,9 @@ func (r *Reader) ISP(ipAddress netip.Addr) (*ISP, error) {
 		return nil, InvalidMethodError{"ISP", r.Metadata().DatabaseType}
 	}
 	result := r.mmdbReader.Lookup(ipAddress)
+	if err := r.checkFound(ipAddress, result); err != nil {
+		return nil, err
+	}
 	var val ISP
 	err := result.Decode(&val)
 	if err != nil {
@@ -318,6 +662,27 @@ func (r *Reader) ISP(ipAddress netip.Addr) (*ISP, error) {
 	return &val, nil
 }
 
+// ISPInto is like ISP, but it decodes the record into the provided ISP
+// struct, rather than allocating a new one. Any data in isp from a previous
+// lookup is overwritten.
+func (r *Reader) ISPInto(ipAddress netip.Addr, isp *ISP) error {
+	if isISP&r.databaseType == 0 {
+		return InvalidMethodError{"ISP", r.Metadata().DatabaseType}
+	}
+	result := r.mmdbReader.Lookup(ipAddress)
+	*isp = ISP{}
+	if err := r.checkFound(ipAddress, result); err != nil {
+		return err
+	}
+	err := result.Decode(isp)
+	if err != nil {
+		return err
+	}
+	isp.IPAddress = ipAddress
+	isp.Network = result.Prefix()
+	return nil
+}
+
 // Metadata takes no arguments and returns a struct containing metadata about
 // the MaxMind database in use by the Reader.
 func (r *Reader) Metadata() maxminddb.Metadata {
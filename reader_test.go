@@ -1,10 +1,13 @@
 package geoip2
 
 import (
+	"io/fs"
 	"math/rand"
 	"net"
 	"net/netip"
+	"os"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -116,6 +119,35 @@ func TestReader(t *testing.T) {
 	assert.True(t, record.Traits.Network.Contains(testAddr))
 }
 
+func TestOpenFS(t *testing.T) {
+	data, err := os.ReadFile("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+
+	tests := map[string]fs.FS{
+		"os.DirFS": os.DirFS("test-data/test-data"),
+		"fstest.MapFS": fstest.MapFS{
+			"GeoIP2-City-Test.mmdb": &fstest.MapFile{Data: data},
+		},
+	}
+
+	for name, fsys := range tests {
+		t.Run(name, func(t *testing.T) {
+			reader, err := OpenFS(fsys, "GeoIP2-City-Test.mmdb")
+			require.NoError(t, err)
+			defer reader.Close()
+
+			assert.Equal(t, "GeoIP2-City", reader.Metadata().DatabaseType)
+
+			record, err := reader.City(netip.MustParseAddr("81.2.69.160"))
+			require.NoError(t, err)
+			assert.Equal(t, "London", record.City.Names.English)
+		})
+	}
+
+	_, err = OpenFS(os.DirFS("test-data/test-data"), "missing.mmdb")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
 func TestIsAnycast(t *testing.T) {
 	for _, test := range []string{"Country", "City", "Enterprise"} {
 		t.Run(test, func(t *testing.T) {
@@ -165,6 +197,49 @@ func TestAnonymousIP(t *testing.T) {
 	assert.True(t, record.Network.Contains(testAddr))
 }
 
+func TestAnonymousPlus(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP-Anonymous-Plus-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	testAddr := netip.MustParseAddr("1.2.0.1")
+	record, err := reader.AnonymousPlus(testAddr)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint16(30), record.AnonymizerConfidence)
+	assert.Equal(t, "2025-04-14", record.NetworkLastSeen)
+	assert.Equal(t, "foo", record.ProviderName)
+	assert.True(t, record.IsAnonymous)
+	assert.True(t, record.IsAnonymousVPN)
+	assert.False(t, record.IsHostingProvider)
+	assert.False(t, record.IsPublicProxy)
+	assert.False(t, record.IsTorExitNode)
+	assert.False(t, record.IsResidentialProxy)
+	assert.True(t, record.HasData())
+
+	assert.Equal(t, testAddr, record.IPAddress)
+	assert.Equal(t, netip.MustParsePrefix("1.2.0.1/32"), record.Network)
+
+	// The Anonymous Plus database also supports the AnonymousIP method.
+	anonIP, err := reader.AnonymousIP(testAddr)
+	require.NoError(t, err)
+	assert.True(t, anonIP.IsAnonymousVPN)
+
+	var into AnonymousPlus
+	require.NoError(t, reader.AnonymousPlusInto(testAddr, &into))
+	assert.Equal(t, record, &into)
+
+	_, err = reader.City(testAddr)
+	require.ErrorAs(t, err, &InvalidMethodError{})
+
+	anonReader, err := Open("test-data/test-data/GeoIP2-Anonymous-IP-Test.mmdb")
+	require.NoError(t, err)
+	defer anonReader.Close()
+
+	_, err = anonReader.AnonymousPlus(testAddr)
+	require.ErrorAs(t, err, &InvalidMethodError{})
+}
+
 func TestASN(t *testing.T) {
 	reader, err := Open("test-data/test-data/GeoLite2-ASN-Test.mmdb")
 	require.NoError(t, err)
@@ -272,6 +347,168 @@ func TestISP(t *testing.T) {
 	assert.Equal(t, "Verizon Wireless", record.Organization)
 }
 
+func TestCityInto(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	londonAddr := netip.MustParseAddr("81.2.69.160")
+	anycastAddr := netip.MustParseAddr("214.1.1.0")
+
+	var record City
+	require.NoError(t, reader.CityInto(londonAddr, &record))
+	expected, err := reader.City(londonAddr)
+	require.NoError(t, err)
+	assert.Equal(t, *expected, record)
+
+	// Fields from the previous lookup that are not in the new record must be
+	// cleared.
+	require.NoError(t, reader.CityInto(anycastAddr, &record))
+	expected, err = reader.City(anycastAddr)
+	require.NoError(t, err)
+	assert.Equal(t, *expected, record)
+	assert.True(t, record.Traits.IsAnycast)
+	assert.Empty(t, record.City.Names.English)
+	assert.Empty(t, record.Subdivisions)
+	assert.Equal(t, expected.Location.HasCoordinates(), record.Location.HasCoordinates())
+
+	require.NoError(t, reader.CityInto(londonAddr, &record))
+	assert.Equal(t, "London", record.City.Names.English)
+	assert.InEpsilon(t, 51.5142, *record.Location.Latitude, 1e-10)
+	assert.False(t, record.Traits.IsAnycast)
+
+	// A copy of a cached record shares its coordinates and subdivisions with
+	// the cache, which must not be modified.
+	cache := NewRecordCachingReader(reader)
+	cached, err := cache.City(londonAddr)
+	require.NoError(t, err)
+	record = *cached
+	require.NoError(t, reader.CityInto(netip.MustParseAddr("216.160.83.56"), &record))
+	assert.Equal(t, "Milton", record.City.Names.English)
+	cached, err = cache.City(londonAddr)
+	require.NoError(t, err)
+	assert.InEpsilon(t, 51.5142, *cached.Location.Latitude, 1e-10)
+	assert.InEpsilon(t, -0.0931, *cached.Location.Longitude, 1e-10)
+	assert.Equal(t, "ENG", cached.Subdivisions[0].ISOCode)
+
+	var asn ASN
+	err = reader.ASNInto(londonAddr, &asn)
+	require.ErrorAs(t, err, &InvalidMethodError{})
+}
+
+func TestIntoMethods(t *testing.T) {
+	tests := []struct {
+		file string
+		ip   string
+		test func(t *testing.T, reader *Reader, ip netip.Addr)
+	}{
+		{
+			file: "GeoIP2-Enterprise-Test.mmdb",
+			ip:   "74.209.24.0",
+			test: func(t *testing.T, reader *Reader, ip netip.Addr) {
+				expected, err := reader.Enterprise(ip)
+				require.NoError(t, err)
+				var record Enterprise
+				require.NoError(t, reader.EnterpriseInto(ip, &record))
+				assert.Equal(t, *expected, record)
+			},
+		},
+		{
+			file: "GeoIP2-Country-Test.mmdb",
+			ip:   "81.2.69.160",
+			test: func(t *testing.T, reader *Reader, ip netip.Addr) {
+				expected, err := reader.Country(ip)
+				require.NoError(t, err)
+				var record Country
+				require.NoError(t, reader.CountryInto(ip, &record))
+				assert.Equal(t, *expected, record)
+			},
+		},
+		{
+			file: "GeoIP2-Anonymous-IP-Test.mmdb",
+			ip:   "1.2.0.0",
+			test: func(t *testing.T, reader *Reader, ip netip.Addr) {
+				expected, err := reader.AnonymousIP(ip)
+				require.NoError(t, err)
+				var record AnonymousIP
+				require.NoError(t, reader.AnonymousIPInto(ip, &record))
+				assert.Equal(t, *expected, record)
+			},
+		},
+		{
+			file: "GeoLite2-ASN-Test.mmdb",
+			ip:   "1.128.0.0",
+			test: func(t *testing.T, reader *Reader, ip netip.Addr) {
+				expected, err := reader.ASN(ip)
+				require.NoError(t, err)
+				var record ASN
+				require.NoError(t, reader.ASNInto(ip, &record))
+				assert.Equal(t, *expected, record)
+			},
+		},
+		{
+			file: "GeoIP2-Connection-Type-Test.mmdb",
+			ip:   "1.0.1.0",
+			test: func(t *testing.T, reader *Reader, ip netip.Addr) {
+				expected, err := reader.ConnectionType(ip)
+				require.NoError(t, err)
+				var record ConnectionType
+				require.NoError(t, reader.ConnectionTypeInto(ip, &record))
+				assert.Equal(t, *expected, record)
+			},
+		},
+		{
+			file: "GeoIP2-Domain-Test.mmdb",
+			ip:   "1.2.0.0",
+			test: func(t *testing.T, reader *Reader, ip netip.Addr) {
+				expected, err := reader.Domain(ip)
+				require.NoError(t, err)
+				var record Domain
+				require.NoError(t, reader.DomainInto(ip, &record))
+				assert.Equal(t, *expected, record)
+			},
+		},
+		{
+			file: "GeoIP2-ISP-Test.mmdb",
+			ip:   "149.101.100.0",
+			test: func(t *testing.T, reader *Reader, ip netip.Addr) {
+				expected, err := reader.ISP(ip)
+				require.NoError(t, err)
+				var record ISP
+				require.NoError(t, reader.ISPInto(ip, &record))
+				assert.Equal(t, *expected, record)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			reader, err := Open("test-data/test-data/" + test.file)
+			require.NoError(t, err)
+			defer reader.Close()
+
+			test.test(t, reader, netip.MustParseAddr(test.ip))
+		})
+	}
+}
+
+func TestIntoAllocations(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	ip := netip.MustParseAddr("81.2.69.160")
+
+	var record City
+	intoAllocs := testing.AllocsPerRun(100, func() {
+		_ = reader.CityInto(ip, &record)
+	})
+	allocs := testing.AllocsPerRun(100, func() {
+		cityResult, _ = reader.City(ip)
+	})
+	assert.Less(t, intoAllocs, allocs)
+}
+
 // This ensures the compiler does not optimize away the function call.
 var cityResult *City
 
@@ -299,6 +536,30 @@ func BenchmarkCity(b *testing.B) {
 	cityResult = city
 }
 
+func BenchmarkCityInto(b *testing.B) {
+	db, err := Open("GeoLite2-City.mmdb")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+
+	//nolint:gosec // this is just a benchmark
+	r := rand.New(rand.NewSource(0))
+
+	city := &City{}
+
+	ip := make(net.IP, 4)
+	for range b.N {
+		randomIPv4Address(r, ip)
+		addr, _ := netip.AddrFromSlice(ip)
+		err = db.CityInto(addr, city)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	cityResult = city
+}
+
 // This ensures the compiler does not optimize away the function call.
 var asnResult *ASN
 
@@ -326,6 +587,30 @@ func BenchmarkASN(b *testing.B) {
 	asnResult = asn
 }
 
+func BenchmarkASNInto(b *testing.B) {
+	db, err := Open("GeoLite2-ASN.mmdb")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+
+	//nolint:gosec // this is just a benchmark
+	r := rand.New(rand.NewSource(0))
+
+	asn := &ASN{}
+
+	ip := make(net.IP, 4)
+	for range b.N {
+		randomIPv4Address(r, ip)
+		addr, _ := netip.AddrFromSlice(ip)
+		err = db.ASNInto(addr, asn)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	asnResult = asn
+}
+
 func randomIPv4Address(r *rand.Rand, ip net.IP) {
 	num := r.Uint32()
 	ip[0] = byte(num >> 24)
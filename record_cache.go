@@ -0,0 +1,148 @@
+package geoip2
+
+import (
+	"net/netip"
+	"sync"
+
+	"github.com/oschwald/maxminddb-golang/v2"
+)
+
+type recordKey struct {
+	offset uintptr
+	method Method
+}
+
+// RecordCachingReader wraps a Reader, decoding each record in the database
+// at most once. In the City, Country, and Enterprise databases, many networks
+// share the same record, e.g., that of a city. Rather than decoding the record
+// on every lookup, the decoded record is cached by its offset in the data
+// section of the database and shared by all the networks pointing to it. This
+// greatly reduces the CPU used when looking up many IP addresses.
+//
+// Unlike CachingReader, the cache is not bounded; it grows to at most one
+// entry per distinct record in the database that has been looked up.
+//
+// The structs returned are copies of the cached records with IPAddress and
+// Network set for the lookup. However, they share the memory referenced by
+// slices and pointers, e.g., Subdivisions, with the cached record, so this
+// memory must not be modified.
+type RecordCachingReader struct {
+	reader  *Reader
+	records map[recordKey]any
+	mu      sync.RWMutex
+}
+
+// NewRecordCachingReader returns a RecordCachingReader performing lookups on
+// reader. The RecordCachingReader does not take ownership of reader, which
+// must be closed by the caller once it is no longer in use.
+func NewRecordCachingReader(reader *Reader) *RecordCachingReader {
+	return &RecordCachingReader{
+		reader:  reader,
+		records: map[recordKey]any{},
+	}
+}
+
+// recordCachedLookup performs a lookup, decoding the record only if it is not
+// yet cached. fields returns pointers to the IPAddress and Network fields of a
+// record.
+func recordCachedLookup[T any](
+	c *RecordCachingReader,
+	ipAddress netip.Addr,
+	method Method,
+	fields func(*T) (*netip.Addr, *netip.Prefix),
+) (*T, error) {
+	r := c.reader
+	if databaseType(method)&r.databaseType == 0 {
+		return nil, InvalidMethodError{method.String(), r.Metadata().DatabaseType}
+	}
+
+	result := r.mmdbReader.Lookup(ipAddress)
+	if err := r.checkFound(ipAddress, result); err != nil {
+		return nil, err
+	}
+	var record T
+	if err := result.Err(); err != nil {
+		return &record, err
+	}
+	if result.Found() {
+		cached, err := c.record(result, method, func(result maxminddb.Result) (any, error) {
+			var val T
+			err := r.decode(result, &val)
+			return &val, err
+		})
+		if err != nil {
+			return &record, err
+		}
+		record = *cached.(*T)
+	}
+
+	ip, network := fields(&record)
+	*ip = ipAddress
+	*network = result.Prefix()
+	return &record, nil
+}
+
+func (c *RecordCachingReader) record(
+	result maxminddb.Result,
+	method Method,
+	decode func(maxminddb.Result) (any, error),
+) (any, error) {
+	key := recordKey{offset: result.Offset(), method: method}
+
+	c.mu.RLock()
+	record, ok := c.records[key]
+	c.mu.RUnlock()
+	if ok {
+		return record, nil
+	}
+
+	record, err := decode(result)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Another goroutine may have decoded the same record concurrently.
+	if existing, ok := c.records[key]; ok {
+		return existing, nil
+	}
+	c.records[key] = record
+	return record, nil
+}
+
+// Enterprise takes an IP address as a netip.Addr and returns an Enterprise
+// struct and/or an error, decoding the record only if it is not yet cached.
+// See Reader.Enterprise.
+func (c *RecordCachingReader) Enterprise(ipAddress netip.Addr) (*Enterprise, error) {
+	return recordCachedLookup(c, ipAddress, MethodEnterprise,
+		func(r *Enterprise) (*netip.Addr, *netip.Prefix) {
+			return &r.Traits.IPAddress, &r.Traits.Network
+		})
+}
+
+// City takes an IP address as a netip.Addr and returns a City struct and/or
+// an error, decoding the record only if it is not yet cached. See
+// Reader.City.
+func (c *RecordCachingReader) City(ipAddress netip.Addr) (*City, error) {
+	return recordCachedLookup(c, ipAddress, MethodCity,
+		func(r *City) (*netip.Addr, *netip.Prefix) {
+			return &r.Traits.IPAddress, &r.Traits.Network
+		})
+}
+
+// Country takes an IP address as a netip.Addr and returns a Country struct
+// and/or an error, decoding the record only if it is not yet cached. See
+// Reader.Country.
+func (c *RecordCachingReader) Country(ipAddress netip.Addr) (*Country, error) {
+	return recordCachedLookup(c, ipAddress, MethodCountry,
+		func(r *Country) (*netip.Addr, *netip.Prefix) {
+			return &r.Traits.IPAddress, &r.Traits.Network
+		})
+}
+
+// Metadata takes no arguments and returns a struct containing metadata about
+// the MaxMind database in use by the Reader.
+func (c *RecordCachingReader) Metadata() maxminddb.Metadata {
+	return c.reader.Metadata()
+}
@@ -0,0 +1,85 @@
+package geoip2
+
+import (
+	"math/rand"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordCachingReader(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	cache := NewRecordCachingReader(reader)
+
+	for _, ip := range []string{
+		"81.2.69.160",
+		"81.2.69.161",
+		"89.160.20.112",
+		"216.160.83.56",
+		"2001:218::",
+		"1.1.1.1",
+	} {
+		addr := netip.MustParseAddr(ip)
+
+		expected, err := reader.City(addr)
+		require.NoError(t, err)
+		record, err := cache.City(addr)
+		require.NoError(t, err)
+		assert.Equal(t, expected, record, ip)
+
+		expectedCountry, err := reader.Country(addr)
+		require.NoError(t, err)
+		country, err := cache.Country(addr)
+		require.NoError(t, err)
+		assert.Equal(t, expectedCountry, country, ip)
+	}
+
+	first, err := cache.City(netip.MustParseAddr("81.2.69.160"))
+	require.NoError(t, err)
+	second, err := cache.City(netip.MustParseAddr("81.2.69.161"))
+	require.NoError(t, err)
+	require.NotNil(t, first.Location.Latitude)
+	assert.Same(t, first.Location.Latitude, second.Location.Latitude)
+	assert.Equal(t, netip.MustParseAddr("81.2.69.161"), second.Traits.IPAddress)
+
+	// Modifying the returned struct must not affect the cache.
+	first.City.Names.English = "Paris"
+	third, err := cache.City(netip.MustParseAddr("81.2.69.160"))
+	require.NoError(t, err)
+	assert.Equal(t, "London", third.City.Names.English)
+
+	_, err = cache.Enterprise(netip.MustParseAddr("81.2.69.160"))
+	require.ErrorAs(t, err, &InvalidMethodError{})
+}
+
+func BenchmarkRecordCachingReaderCity(b *testing.B) {
+	db, err := Open("GeoLite2-City.mmdb")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+
+	cache := NewRecordCachingReader(db)
+
+	//nolint:gosec // this is just a benchmark
+	r := rand.New(rand.NewSource(0))
+
+	var city *City
+
+	ip := make(net.IP, 4)
+	for range b.N {
+		randomIPv4Address(r, ip)
+		addr, _ := netip.AddrFromSlice(ip)
+		city, err = cache.City(addr)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	cityResult = city
+}
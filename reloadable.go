@@ -0,0 +1,297 @@
+package geoip2
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+
+	"github.com/oschwald/maxminddb-golang/v2"
+)
+
+var errReaderClosed = errors.New("geoip2: cannot perform lookup on a closed reader")
+
+// IncompatibleDatabaseTypeError is returned when a ReloadableReader is asked
+// to swap in a database that does not support every lookup method supported
+// by the database currently in use. For instance, replacing a City database
+// with an ASN database.
+type IncompatibleDatabaseTypeError struct {
+	CurrentDatabaseType string
+	NewDatabaseType     string
+}
+
+func (e IncompatibleDatabaseTypeError) Error() string {
+	return fmt.Sprintf(`geoip2: cannot replace the %s database with the %s database`,
+		e.CurrentDatabaseType, e.NewDatabaseType)
+}
+
+// refCountedReader tracks the lookups in flight on a Reader so that it is
+// only closed once the last of them has finished.
+type refCountedReader struct {
+	reader *Reader
+	// refs is the number of in-flight lookups plus one for the reference
+	// held by the ReloadableReader while this reader is current.
+	refs atomic.Int64
+}
+
+func newRefCountedReader(reader *Reader) *refCountedReader {
+	rc := &refCountedReader{reader: reader}
+	rc.refs.Store(1)
+	return rc
+}
+
+// acquire takes a reference to the reader. It returns false if the reader
+// has already been released for the last time.
+func (rc *refCountedReader) acquire() bool {
+	for {
+		n := rc.refs.Load()
+		if n == 0 {
+			return false
+		}
+		if rc.refs.CompareAndSwap(n, n+1) {
+			return true
+		}
+	}
+}
+
+// release drops a reference to the reader, closing it if this was the last
+// one.
+func (rc *refCountedReader) release() error {
+	if rc.refs.Add(-1) == 0 {
+		return rc.reader.Close()
+	}
+	return nil
+}
+
+// ReloadableReader is a Reader whose underlying database can be replaced
+// while it is in use. Lookups always use the most recently loaded database.
+// A replaced database is closed only once every lookup that started on it
+// has finished, so it is safe to reload while other goroutines are
+// performing lookups.
+//
+// A ReloadableReader only accepts a new database if it supports every lookup
+// method of the current one. For instance, a GeoLite2-City database may be
+// replaced with a GeoIP2-City or GeoIP2-Enterprise database, but not with a
+// GeoLite2-ASN database.
+type ReloadableReader struct {
+	current atomic.Pointer[refCountedReader]
+	// swaps counts the databases swapped in. It is incremented after the new
+	// database is stored.
+	swaps atomic.Uint64
+	// watchStop and watchDone are set when the file is being watched. See
+	// OpenWatched.
+	watchStop chan struct{}
+	watchDone chan struct{}
+	// watchCallback is set while the watching goroutine calls a function
+	// provided with a WatchOption. See stopWatching.
+	watchCallback atomic.Bool
+	// mu serializes reloads and Close.
+	mu      sync.Mutex
+	file    string
+	options []ReaderOption
+}
+
+// OpenReloadable takes a string path to a file and returns a ReloadableReader
+// struct or an error. The database file is opened using Open with the
+// provided options, which are also used when reloading it. Use the Reload
+// method to reopen the file after it has been updated and the Close method to
+// return the resources to the system.
+func OpenReloadable(file string, options ...ReaderOption) (*ReloadableReader, error) {
+	reader, err := openReloadable(file, options)
+	if err != nil {
+		return nil, err
+	}
+	r := &ReloadableReader{file: file, options: options}
+	r.current.Store(newRefCountedReader(reader))
+	return r, nil
+}
+
+// openReloadable opens file as with Open. Unlike Open, it closes the Reader
+// if there is an error, e.g., an UnknownDatabaseTypeError, as a
+// ReloadableReader never uses it.
+func openReloadable(file string, options []ReaderOption) (*Reader, error) {
+	reader, err := Open(file, options...)
+	if err != nil {
+		if reader != nil {
+			_ = reader.Close()
+		}
+		return nil, err
+	}
+	return reader, nil
+}
+
+// Reload reopens the file the ReloadableReader was opened with, or most
+// recently reloaded from, and swaps it in. See Swap for details.
+func (r *ReloadableReader) Reload() error {
+	r.mu.Lock()
+	file := r.file
+	r.mu.Unlock()
+	return r.ReloadFrom(file)
+}
+
+// ReloadFrom opens the database at the provided path and swaps it in. Later
+// calls to Reload will reopen this path. See Swap for details.
+func (r *ReloadableReader) ReloadFrom(file string) error {
+	reader, err := openReloadable(file, r.options)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	swapped, err := r.swapLocked(reader)
+	if !swapped {
+		_ = reader.Close()
+		return err
+	}
+	r.file = file
+	return err
+}
+
+// Swap replaces the database in use with the provided Reader, which is then
+// owned by the ReloadableReader. The previous database is closed once all
+// lookups in progress on it have finished.
+//
+// If the new database does not support every lookup method of the current
+// one, an IncompatibleDatabaseTypeError is returned and the current database
+// remains in use. In that case, as well as when the ReloadableReader has
+// been closed, the caller keeps ownership of reader.
+//
+// If closing the previous database fails and no lookups were in progress on
+// it, that error is returned. The new database is in use regardless.
+func (r *ReloadableReader) Swap(reader *Reader) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err := r.swapLocked(reader)
+	return err
+}
+
+// swapLocked swaps in reader, reporting whether it did so. r.mu must be held.
+func (r *ReloadableReader) swapLocked(reader *Reader) (bool, error) {
+	old := r.current.Load()
+	if old == nil {
+		return false, errReaderClosed
+	}
+	if reader.databaseType&old.reader.databaseType != old.reader.databaseType {
+		return false, IncompatibleDatabaseTypeError{
+			CurrentDatabaseType: old.reader.Metadata().DatabaseType,
+			NewDatabaseType:     reader.Metadata().DatabaseType,
+		}
+	}
+	r.current.Store(newRefCountedReader(reader))
+	r.swaps.Add(1)
+	return true, old.release()
+}
+
+// acquire returns the current reader with a reference taken on it. The
+// caller must release the reference when done with it.
+func (r *ReloadableReader) acquire() (*refCountedReader, error) {
+	for {
+		rc := r.current.Load()
+		if rc == nil {
+			return nil, errReaderClosed
+		}
+		if rc.acquire() {
+			return rc, nil
+		}
+		// The reader was swapped out and released between the load and the
+		// acquire. Try again with its replacement.
+	}
+}
+
+func reloadableLookup[T any](
+	r *ReloadableReader,
+	ipAddress netip.Addr,
+	lookup func(*Reader, netip.Addr) (*T, error),
+) (*T, error) {
+	rc, err := r.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.release() //nolint:errcheck // there is no caller to report the error to
+	return lookup(rc.reader, ipAddress)
+}
+
+// Enterprise takes an IP address as a netip.Addr and returns an Enterprise
+// struct and/or an error using the current database. See Reader.Enterprise.
+func (r *ReloadableReader) Enterprise(ipAddress netip.Addr) (*Enterprise, error) {
+	return reloadableLookup(r, ipAddress, (*Reader).Enterprise)
+}
+
+// City takes an IP address as a netip.Addr and returns a City struct and/or
+// an error using the current database. See Reader.City.
+func (r *ReloadableReader) City(ipAddress netip.Addr) (*City, error) {
+	return reloadableLookup(r, ipAddress, (*Reader).City)
+}
+
+// Country takes an IP address as a netip.Addr and returns a Country struct
+// and/or an error using the current database. See Reader.Country.
+func (r *ReloadableReader) Country(ipAddress netip.Addr) (*Country, error) {
+	return reloadableLookup(r, ipAddress, (*Reader).Country)
+}
+
+// AnonymousIP takes an IP address as a netip.Addr and returns a AnonymousIP
+// struct and/or an error using the current database. See Reader.AnonymousIP.
+func (r *ReloadableReader) AnonymousIP(ipAddress netip.Addr) (*AnonymousIP, error) {
+	return reloadableLookup(r, ipAddress, (*Reader).AnonymousIP)
+}
+
+// AnonymousPlus takes an IP address as a netip.Addr and returns a
+// AnonymousPlus struct and/or an error using the current database. See
+// Reader.AnonymousPlus.
+func (r *ReloadableReader) AnonymousPlus(ipAddress netip.Addr) (*AnonymousPlus, error) {
+	return reloadableLookup(r, ipAddress, (*Reader).AnonymousPlus)
+}
+
+// ASN takes an IP address as a netip.Addr and returns a ASN struct and/or an
+// error using the current database. See Reader.ASN.
+func (r *ReloadableReader) ASN(ipAddress netip.Addr) (*ASN, error) {
+	return reloadableLookup(r, ipAddress, (*Reader).ASN)
+}
+
+// ConnectionType takes an IP address as a netip.Addr and returns a
+// ConnectionType struct and/or an error using the current database. See
+// Reader.ConnectionType.
+func (r *ReloadableReader) ConnectionType(ipAddress netip.Addr) (*ConnectionType, error) {
+	return reloadableLookup(r, ipAddress, (*Reader).ConnectionType)
+}
+
+// Domain takes an IP address as a netip.Addr and returns a Domain struct
+// and/or an error using the current database. See Reader.Domain.
+func (r *ReloadableReader) Domain(ipAddress netip.Addr) (*Domain, error) {
+	return reloadableLookup(r, ipAddress, (*Reader).Domain)
+}
+
+// ISP takes an IP address as a netip.Addr and returns a ISP struct and/or an
+// error using the current database. See Reader.ISP.
+func (r *ReloadableReader) ISP(ipAddress netip.Addr) (*ISP, error) {
+	return reloadableLookup(r, ipAddress, (*Reader).ISP)
+}
+
+// Metadata takes no arguments and returns a struct containing metadata about
+// the MaxMind database currently in use by the ReloadableReader. The zero
+// value is returned if the ReloadableReader has been closed.
+func (r *ReloadableReader) Metadata() maxminddb.Metadata {
+	rc, err := r.acquire()
+	if err != nil {
+		return maxminddb.Metadata{}
+	}
+	defer rc.release() //nolint:errcheck // there is no caller to report the error to
+	return rc.reader.Metadata()
+}
+
+// Close closes the current database once all lookups in progress on it have
+// finished. Lookups started after Close return an error. If the file is being
+// watched for changes, Close stops watching it. It does not wait for an
+// OnReload or OnReloadError function that is running to return.
+func (r *ReloadableReader) Close() error {
+	r.stopWatching()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rc := r.current.Swap(nil)
+	if rc == nil {
+		return nil
+	}
+	return rc.release()
+}
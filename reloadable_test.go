@@ -0,0 +1,106 @@
+package geoip2
+
+import (
+	"net/netip"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadableReader(t *testing.T) {
+	reader, err := OpenReloadable("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	testAddr := netip.MustParseAddr("81.2.69.160")
+	record, err := reader.City(testAddr)
+	require.NoError(t, err)
+	assert.Equal(t, "London", record.City.Names.English)
+	assert.Equal(t, testAddr, record.Traits.IPAddress)
+	assert.Equal(t, "GeoIP2-City", reader.Metadata().DatabaseType)
+
+	// The Enterprise database supports every method of the City database.
+	require.NoError(t, reader.ReloadFrom("test-data/test-data/GeoIP2-Enterprise-Test.mmdb"))
+	assert.Equal(t, "GeoIP2-Enterprise", reader.Metadata().DatabaseType)
+
+	enterprise, err := reader.Enterprise(netip.MustParseAddr("74.209.24.0"))
+	require.NoError(t, err)
+	assert.Equal(t, uint(14671), enterprise.Traits.AutonomousSystemNumber)
+
+	require.NoError(t, reader.Reload())
+	assert.Equal(t, "GeoIP2-Enterprise", reader.Metadata().DatabaseType)
+}
+
+func TestReloadableReaderIncompatible(t *testing.T) {
+	reader, err := OpenReloadable("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	err = reader.ReloadFrom("test-data/test-data/GeoLite2-ASN-Test.mmdb")
+	require.ErrorAs(t, err, &IncompatibleDatabaseTypeError{})
+	assert.Equal(
+		t,
+		"geoip2: cannot replace the GeoIP2-City database with the GeoLite2-ASN database",
+		err.Error(),
+	)
+
+	// The original database remains in use, as does its path.
+	assert.Equal(t, "GeoIP2-City", reader.Metadata().DatabaseType)
+	require.NoError(t, reader.Reload())
+	assert.Equal(t, "GeoIP2-City", reader.Metadata().DatabaseType)
+
+	_, err = reader.ASN(netip.MustParseAddr("1.128.0.0"))
+	assert.ErrorAs(t, err, &InvalidMethodError{})
+}
+
+func TestReloadableReaderClose(t *testing.T) {
+	reader, err := OpenReloadable("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+
+	require.NoError(t, reader.Close())
+	require.NoError(t, reader.Close())
+
+	_, err = reader.City(netip.MustParseAddr("81.2.69.160"))
+	require.Error(t, err)
+	require.Error(t, reader.Reload())
+	assert.Equal(t, "", reader.Metadata().DatabaseType)
+}
+
+func TestReloadableReaderConcurrentReload(t *testing.T) {
+	reader, err := OpenReloadable("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	testAddr := netip.MustParseAddr("81.2.69.160")
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				record, err := reader.City(testAddr)
+				if !assert.NoError(t, err) {
+					return
+				}
+				if !assert.Equal(t, "GB", record.Country.ISOCode) {
+					return
+				}
+			}
+		}()
+	}
+
+	for range 50 {
+		require.NoError(t, reader.Reload())
+	}
+	close(done)
+	wg.Wait()
+}
@@ -0,0 +1,124 @@
+package geoip2
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// ReservedKind is the kind of a special-purpose IP address range.
+type ReservedKind int
+
+// The kinds of special-purpose IP address ranges.
+const (
+	// ReservedUnspecified is the "this network" range, 0.0.0.0/8, and the
+	// unspecified IPv6 address, ::.
+	ReservedUnspecified ReservedKind = iota + 1
+	// ReservedPrivate is the private IPv4 ranges of RFC 1918 and the IPv6
+	// unique local addresses of RFC 4193.
+	ReservedPrivate
+	// ReservedSharedAddressSpace is the range used by carrier-grade NAT,
+	// 100.64.0.0/10, as per RFC 6598.
+	ReservedSharedAddressSpace
+	// ReservedLoopback is the loopback addresses.
+	ReservedLoopback
+	// ReservedLinkLocal is the link-local addresses.
+	ReservedLinkLocal
+	// ReservedDocumentation is the ranges for use in documentation, e.g.,
+	// 192.0.2.0/24 and 2001:db8::/32.
+	ReservedDocumentation
+	// ReservedBenchmarking is the ranges for benchmarking network devices.
+	ReservedBenchmarking
+	// ReservedMulticast is the multicast addresses.
+	ReservedMulticast
+	// ReservedFutureUse is the IPv4 range reserved for future use,
+	// 240.0.0.0/4, which includes the limited broadcast address.
+	ReservedFutureUse
+)
+
+// String returns a description of the kind, e.g., "private".
+func (k ReservedKind) String() string {
+	switch k {
+	case ReservedUnspecified:
+		return "unspecified"
+	case ReservedPrivate:
+		return "private"
+	case ReservedSharedAddressSpace:
+		return "shared address space"
+	case ReservedLoopback:
+		return "loopback"
+	case ReservedLinkLocal:
+		return "link-local"
+	case ReservedDocumentation:
+		return "documentation"
+	case ReservedBenchmarking:
+		return "benchmarking"
+	case ReservedMulticast:
+		return "multicast"
+	case ReservedFutureUse:
+		return "reserved for future use"
+	default:
+		return "unknown"
+	}
+}
+
+// ReservedRange is a special-purpose IP address range, which is not routed
+// on the public internet and therefore has no geolocation data.
+type ReservedRange struct {
+	// Network is the range.
+	Network netip.Prefix
+	// RFC is the RFC defining the range, e.g., "RFC 1918".
+	RFC string
+	// Kind is the kind of the range.
+	Kind ReservedKind
+}
+
+var reservedRanges = []ReservedRange{
+	{netip.MustParsePrefix("0.0.0.0/8"), "RFC 1122", ReservedUnspecified},
+	{netip.MustParsePrefix("10.0.0.0/8"), "RFC 1918", ReservedPrivate},
+	{netip.MustParsePrefix("100.64.0.0/10"), "RFC 6598", ReservedSharedAddressSpace},
+	{netip.MustParsePrefix("127.0.0.0/8"), "RFC 1122", ReservedLoopback},
+	{netip.MustParsePrefix("169.254.0.0/16"), "RFC 3927", ReservedLinkLocal},
+	{netip.MustParsePrefix("172.16.0.0/12"), "RFC 1918", ReservedPrivate},
+	{netip.MustParsePrefix("192.0.2.0/24"), "RFC 5737", ReservedDocumentation},
+	{netip.MustParsePrefix("192.168.0.0/16"), "RFC 1918", ReservedPrivate},
+	{netip.MustParsePrefix("198.18.0.0/15"), "RFC 2544", ReservedBenchmarking},
+	{netip.MustParsePrefix("198.51.100.0/24"), "RFC 5737", ReservedDocumentation},
+	{netip.MustParsePrefix("203.0.113.0/24"), "RFC 5737", ReservedDocumentation},
+	{netip.MustParsePrefix("224.0.0.0/4"), "RFC 5771", ReservedMulticast},
+	{netip.MustParsePrefix("240.0.0.0/4"), "RFC 1112", ReservedFutureUse},
+	{netip.MustParsePrefix("::/128"), "RFC 4291", ReservedUnspecified},
+	{netip.MustParsePrefix("::1/128"), "RFC 4291", ReservedLoopback},
+	{netip.MustParsePrefix("2001:2::/48"), "RFC 5180", ReservedBenchmarking},
+	{netip.MustParsePrefix("2001:db8::/32"), "RFC 3849", ReservedDocumentation},
+	{netip.MustParsePrefix("3fff::/20"), "RFC 9637", ReservedDocumentation},
+	{netip.MustParsePrefix("fc00::/7"), "RFC 4193", ReservedPrivate},
+	{netip.MustParsePrefix("fe80::/10"), "RFC 4291", ReservedLinkLocal},
+	{netip.MustParsePrefix("ff00::/8"), "RFC 4291", ReservedMulticast},
+}
+
+// LookupReserved returns the special-purpose range containing ipAddress, if
+// any. IPv4-mapped IPv6 addresses are treated as IPv4 addresses.
+func LookupReserved(ipAddress netip.Addr) (ReservedRange, bool) {
+	ip := ipAddress.WithZone("").Unmap()
+	for _, r := range reservedRanges {
+		if r.Network.Contains(ip) {
+			return r, true
+		}
+	}
+	return ReservedRange{}, false
+}
+
+// ReservedAddressError is returned by the lookup methods of a Reader opened
+// with StrictLookups when the database has no record for an IP address in a
+// special-purpose range, e.g., a private network.
+type ReservedAddressError struct {
+	// IPAddress is the IP address looked up.
+	IPAddress netip.Addr
+	// Range is the special-purpose range containing IPAddress.
+	Range ReservedRange
+}
+
+func (e ReservedAddressError) Error() string {
+	return fmt.Sprintf("geoip2: the address %s is in the %s range %s (%s)",
+		e.IPAddress, e.Range.Kind, e.Range.Network, e.Range.RFC)
+}
@@ -0,0 +1,96 @@
+package geoip2
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupReserved(t *testing.T) {
+	tests := []struct {
+		ip      string
+		network string
+		kind    ReservedKind
+	}{
+		{"0.1.2.3", "0.0.0.0/8", ReservedUnspecified},
+		{"10.1.2.3", "10.0.0.0/8", ReservedPrivate},
+		{"172.31.255.255", "172.16.0.0/12", ReservedPrivate},
+		{"192.168.1.1", "192.168.0.0/16", ReservedPrivate},
+		{"::ffff:192.168.1.1", "192.168.0.0/16", ReservedPrivate},
+		{"100.64.0.1", "100.64.0.0/10", ReservedSharedAddressSpace},
+		{"127.0.0.1", "127.0.0.0/8", ReservedLoopback},
+		{"169.254.1.1", "169.254.0.0/16", ReservedLinkLocal},
+		{"198.51.100.7", "198.51.100.0/24", ReservedDocumentation},
+		{"198.19.0.1", "198.18.0.0/15", ReservedBenchmarking},
+		{"239.255.255.250", "224.0.0.0/4", ReservedMulticast},
+		{"255.255.255.255", "240.0.0.0/4", ReservedFutureUse},
+		{"::", "::/128", ReservedUnspecified},
+		{"::1", "::1/128", ReservedLoopback},
+		{"fd12:3456::1", "fc00::/7", ReservedPrivate},
+		{"fe80::1%eth0", "fe80::/10", ReservedLinkLocal},
+		{"2001:db8::1", "2001:db8::/32", ReservedDocumentation},
+		{"ff02::1", "ff00::/8", ReservedMulticast},
+	}
+	for _, test := range tests {
+		reserved, ok := LookupReserved(netip.MustParseAddr(test.ip))
+		require.True(t, ok, test.ip)
+		assert.Equal(t, netip.MustParsePrefix(test.network), reserved.Network, test.ip)
+		assert.Equal(t, test.kind, reserved.Kind, test.ip)
+		assert.NotEmpty(t, reserved.RFC, test.ip)
+	}
+
+	for _, ip := range []string{"81.2.69.142", "8.8.8.8", "2001:218::1", "::ffff:1.1.1.1"} {
+		_, ok := LookupReserved(netip.MustParseAddr(ip))
+		assert.False(t, ok, ip)
+	}
+}
+
+func TestStrictLookups(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb", StrictLookups())
+	require.NoError(t, err)
+	defer reader.Close()
+
+	record, err := reader.City(netip.MustParseAddr("81.2.69.160"))
+	require.NoError(t, err)
+	assert.Equal(t, "London", record.City.Names.English)
+
+	unknown := netip.MustParseAddr("1.1.1.1")
+	record, err = reader.City(unknown)
+	assert.Nil(t, record)
+	var notFound AddressNotFoundError
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, unknown, notFound.IPAddress)
+	assert.True(t, notFound.Network.Contains(unknown))
+	assert.Equal(t, "geoip2: the address 1.1.1.1 is not in the database", err.Error())
+
+	private := netip.MustParseAddr("192.168.1.1")
+	_, err = reader.Country(private)
+	var reservedErr ReservedAddressError
+	require.ErrorAs(t, err, &reservedErr)
+	assert.Equal(t, private, reservedErr.IPAddress)
+	assert.Equal(t, ReservedPrivate, reservedErr.Range.Kind)
+	assert.Equal(t,
+		"geoip2: the address 192.168.1.1 is in the private range 192.168.0.0/16 (RFC 1918)",
+		err.Error(),
+	)
+
+	// The data from the previous lookup must not be left in the record.
+	var city City
+	require.NoError(t, reader.CityInto(netip.MustParseAddr("81.2.69.160"), &city))
+	require.ErrorAs(t, reader.CityInto(unknown, &city), &AddressNotFoundError{})
+	assert.False(t, city.HasData())
+
+	_, err = Lookup[City](reader, unknown, MethodCity)
+	require.ErrorAs(t, err, &AddressNotFoundError{})
+
+	// Without StrictLookups, an empty record is returned.
+	lenient, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer lenient.Close()
+
+	record, err = lenient.City(private)
+	require.NoError(t, err)
+	assert.False(t, record.HasData())
+}
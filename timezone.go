@@ -0,0 +1,106 @@
+package geoip2
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// UnknownTimeZoneError is returned when the TimeZone of a Location is empty
+// or is not in the time zone database available to the program.
+type UnknownTimeZoneError struct {
+	// Err is the error returned by time.LoadLocation, if any.
+	Err error
+	// TimeZone is the time zone that could not be loaded.
+	TimeZone string
+}
+
+func (e UnknownTimeZoneError) Error() string {
+	if e.TimeZone == "" {
+		return "geoip2: the location has no time zone"
+	}
+	return fmt.Sprintf("geoip2: unknown time zone %q", e.TimeZone)
+}
+
+func (e UnknownTimeZoneError) Unwrap() error {
+	return e.Err
+}
+
+type timeZoneResult struct {
+	location *time.Location
+	err      error
+}
+
+// timeZones caches the results of time.LoadLocation, which reads the time
+// zone database on every call, by time zone name.
+var timeZones sync.Map
+
+func loadTimeZone(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, UnknownTimeZoneError{}
+	}
+	if result, ok := timeZones.Load(name); ok {
+		r := result.(timeZoneResult)
+		return r.location, r.err
+	}
+	location, err := time.LoadLocation(name)
+	// time.LoadLocation returns the local time zone of the program for
+	// "Local", which is not an IANA time zone.
+	if err == nil && location == time.Local {
+		location, err = nil, fmt.Errorf("%q is not an IANA time zone", name)
+	}
+	if err != nil {
+		err = UnknownTimeZoneError{TimeZone: name, Err: err}
+	}
+	timeZones.Store(name, timeZoneResult{location: location, err: err})
+	return location, err
+}
+
+// TimeLocation returns the *time.Location for the TimeZone of the Location,
+// loaded from the time zone database. The result is cached, so it is cheap to
+// call repeatedly. An UnknownTimeZoneError is returned if the TimeZone is
+// empty or unknown, e.g., because the time zone database available to the
+// program is missing or outdated. Importing time/tzdata embeds a copy of the
+// database in the program.
+func (l Location) TimeLocation() (*time.Location, error) {
+	return loadTimeZone(l.TimeZone)
+}
+
+// LocalTime returns t in the time zone of the Location. See TimeLocation.
+func (l Location) LocalTime(t time.Time) (time.Time, error) {
+	location, err := l.TimeLocation()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(location), nil
+}
+
+// Now returns the current time in the time zone of the Location. See
+// TimeLocation.
+func (l Location) Now() (time.Time, error) {
+	return l.LocalTime(time.Now())
+}
+
+// UTCOffset returns the offset from UTC of the time zone of the Location at
+// t, e.g., one hour for Europe/London during British Summer Time. See
+// TimeLocation.
+func (l Location) UTCOffset(t time.Time) (time.Duration, error) {
+	local, err := l.LocalTime(t)
+	if err != nil {
+		return 0, err
+	}
+	_, offset := local.Zone()
+	return time.Duration(offset) * time.Second, nil
+}
+
+// TimeLocation returns the *time.Location for the time zone of the City
+// record. See Location.TimeLocation.
+func (c City) TimeLocation() (*time.Location, error) {
+	return c.Location.TimeLocation()
+}
+
+// TimeLocation returns the *time.Location for the time zone of the
+// Enterprise record. See Location.TimeLocation.
+func (e Enterprise) TimeLocation() (*time.Location, error) {
+	return e.Location.TimeLocation()
+}
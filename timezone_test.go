@@ -0,0 +1,69 @@
+package geoip2
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocationTimeLocation(t *testing.T) {
+	reader, err := Open("test-data/test-data/GeoIP2-City-Test.mmdb")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	record, err := reader.City(netip.MustParseAddr("81.2.69.160"))
+	require.NoError(t, err)
+	require.Equal(t, "Europe/London", record.Location.TimeZone)
+
+	location, err := record.TimeLocation()
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/London", location.String())
+
+	cached, err := record.Location.TimeLocation()
+	require.NoError(t, err)
+	assert.Same(t, location, cached)
+
+	summer := time.Date(2025, time.July, 1, 12, 0, 0, 0, time.UTC)
+	offset, err := record.Location.UTCOffset(summer)
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, offset)
+
+	winter := time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)
+	offset, err = record.Location.UTCOffset(winter)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), offset)
+
+	local, err := record.Location.LocalTime(summer)
+	require.NoError(t, err)
+	assert.Equal(t, 13, local.Hour())
+	assert.True(t, local.Equal(summer))
+
+	now, err := record.Location.Now()
+	require.NoError(t, err)
+	assert.Equal(t, location, now.Location())
+}
+
+func TestLocationTimeLocationErrors(t *testing.T) {
+	var tzErr UnknownTimeZoneError
+
+	_, err := Location{}.TimeLocation()
+	require.ErrorAs(t, err, &tzErr)
+	assert.Empty(t, tzErr.TimeZone)
+	assert.Equal(t, "geoip2: the location has no time zone", err.Error())
+
+	for _, name := range []string{"Mars/Olympus_Mons", "Local"} {
+		_, err = Location{TimeZone: name}.Now()
+		require.ErrorAs(t, err, &tzErr, name)
+		assert.Equal(t, name, tzErr.TimeZone)
+		require.Error(t, tzErr.Err)
+
+		// The cached error is returned on later calls.
+		_, err = Location{TimeZone: name}.UTCOffset(time.Now())
+		require.ErrorAs(t, err, &UnknownTimeZoneError{})
+		_, ok := timeZones.Load(name)
+		assert.True(t, ok)
+	}
+}
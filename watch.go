@@ -0,0 +1,195 @@
+package geoip2
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang/v2"
+)
+
+const defaultPollInterval = time.Minute
+
+type watchOptions struct {
+	onReload      func(oldMetadata, newMetadata maxminddb.Metadata)
+	onReloadError func(error)
+	readerOptions []ReaderOption
+	interval      time.Duration
+}
+
+// WatchOption are options for OpenWatched.
+type WatchOption func(*watchOptions)
+
+// PollInterval is an option for OpenWatched that sets how often the database
+// file is checked for changes. The default is one minute. OpenWatched returns
+// an error if the interval is not positive.
+func PollInterval(interval time.Duration) WatchOption {
+	return func(opts *watchOptions) {
+		opts.interval = interval
+	}
+}
+
+// OnReload is an option for OpenWatched that sets a function to be called
+// after the database has been reloaded. It receives the metadata of the
+// previous and the new database, e.g., to compare their BuildEpoch values.
+// The function is called on the goroutine watching the file and may call
+// Close. Close does not wait for the function to return, whichever goroutine
+// it is called from, so the function may still be running after Close has
+// returned.
+func OnReload(fn func(oldMetadata, newMetadata maxminddb.Metadata)) WatchOption {
+	return func(opts *watchOptions) {
+		opts.onReload = fn
+	}
+}
+
+// OnReloadError is an option for OpenWatched that sets a function to be
+// called when the database file could not be checked or reloaded. The
+// previous database remains in use when this happens. As with OnReload, the
+// function is called on the goroutine watching the file, may call Close, and
+// may still be running after Close has returned.
+func OnReloadError(fn func(error)) WatchOption {
+	return func(opts *watchOptions) {
+		opts.onReloadError = fn
+	}
+}
+
+// ReaderOptions is an option for OpenWatched that sets the options used to
+// open the database file, initially and when it is reloaded.
+func ReaderOptions(options ...ReaderOption) WatchOption {
+	return func(opts *watchOptions) {
+		opts.readerOptions = options
+	}
+}
+
+// OpenWatched takes a string path to a file and returns a ReloadableReader
+// struct or an error. The file is periodically checked for changes and, when
+// it has been replaced or rewritten, it is reloaded as with Reload. Use the
+// Close method on the ReloadableReader to stop watching the file and return
+// the resources to the system.
+//
+// As the database is memory mapped, updates should be made by writing the new
+// database to a temporary file and renaming it over the old one, as
+// geoipupdate does, rather than by modifying the file in place.
+func OpenWatched(file string, options ...WatchOption) (*ReloadableReader, error) {
+	opts := &watchOptions{interval: defaultPollInterval}
+	for _, option := range options {
+		option(opts)
+	}
+	if opts.interval <= 0 {
+		return nil, fmt.Errorf("geoip2: the poll interval must be positive, not %v", opts.interval)
+	}
+
+	// The file is checked before it is opened so that a replacement made in
+	// between is not missed.
+	stat, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := OpenReloadable(file, opts.readerOptions...)
+	if err != nil {
+		return nil, err
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	r.watchStop = stop
+	r.watchDone = done
+	go r.watch(opts, stat, stop, done)
+	return r, nil
+}
+
+func (r *ReloadableReader) watch(
+	opts *watchOptions,
+	stat os.FileInfo,
+	stop <-chan struct{},
+	done chan<- struct{},
+) {
+	defer close(done)
+
+	ticker := time.NewTicker(opts.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		// A tick may be ready at the same time as stop, e.g., when a callback
+		// called Close.
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		r.mu.Lock()
+		file := r.file
+		r.mu.Unlock()
+
+		newStat, err := os.Stat(file)
+		if err != nil {
+			// Only report a missing file once. It is reloaded as soon as it
+			// reappears.
+			if stat != nil {
+				r.inWatchCallback(func() { opts.reportError(err) })
+			}
+			stat = nil
+			continue
+		}
+		if stat != nil && !fileChanged(stat, newStat) {
+			continue
+		}
+		stat = newStat
+
+		oldMetadata := r.Metadata()
+		if err := r.ReloadFrom(file); err != nil {
+			r.inWatchCallback(func() { opts.reportError(err) })
+			continue
+		}
+		if opts.onReload != nil {
+			newMetadata := r.Metadata()
+			r.inWatchCallback(func() { opts.onReload(oldMetadata, newMetadata) })
+		}
+	}
+}
+
+// inWatchCallback calls fn, a function provided with a WatchOption, while
+// letting stopWatching know not to wait for the watching goroutine, which fn
+// may be called from.
+func (r *ReloadableReader) inWatchCallback(fn func()) {
+	r.watchCallback.Store(true)
+	defer r.watchCallback.Store(false)
+	fn()
+}
+
+func (opts *watchOptions) reportError(err error) {
+	if opts.onReloadError != nil {
+		opts.onReloadError(err)
+	}
+}
+
+func fileChanged(oldStat, newStat os.FileInfo) bool {
+	return !os.SameFile(oldStat, newStat) ||
+		!oldStat.ModTime().Equal(newStat.ModTime()) ||
+		oldStat.Size() != newStat.Size()
+}
+
+// stopWatching stops the goroutine started by OpenWatched, if any, and waits
+// for it to exit. It does not wait while that goroutine is calling a function
+// provided with a WatchOption, as that function may be the caller, in which
+// case waiting would never return. The caller cannot be told apart from
+// another goroutine, so Close may then return before the function does.
+func (r *ReloadableReader) stopWatching() {
+	r.mu.Lock()
+	stop, done := r.watchStop, r.watchDone
+	r.watchStop = nil
+	r.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		if !r.watchCallback.Load() {
+			<-done
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package geoip2
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenWatched(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "GeoIP2-City.mmdb")
+	replaceFile(t, "test-data/test-data/GeoIP2-City-Test.mmdb", file)
+
+	reloads := make(chan [2]maxminddb.Metadata, 1)
+	reloadErrors := make(chan error, 1)
+	reader, err := OpenWatched(
+		file,
+		PollInterval(10*time.Millisecond),
+		OnReload(func(oldMetadata, newMetadata maxminddb.Metadata) {
+			reloads <- [2]maxminddb.Metadata{oldMetadata, newMetadata}
+		}),
+		OnReloadError(func(err error) {
+			reloadErrors <- err
+		}),
+	)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	replaceFile(t, "test-data/test-data/GeoIP2-Enterprise-Test.mmdb", file)
+
+	select {
+	case metadata := <-reloads:
+		assert.Equal(t, "GeoIP2-City", metadata[0].DatabaseType)
+		assert.Equal(t, "GeoIP2-Enterprise", metadata[1].DatabaseType)
+		assert.NotZero(t, metadata[1].BuildEpoch)
+	case err := <-reloadErrors:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for reload")
+	}
+	assert.Equal(t, "GeoIP2-Enterprise", reader.Metadata().DatabaseType)
+
+	// An incompatible database is rejected and the current one kept.
+	replaceFile(t, "test-data/test-data/GeoLite2-ASN-Test.mmdb", file)
+
+	select {
+	case <-reloads:
+		require.FailNow(t, "incompatible database was loaded")
+	case err := <-reloadErrors:
+		require.ErrorAs(t, err, &IncompatibleDatabaseTypeError{})
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for reload error")
+	}
+
+	record, err := reader.Enterprise(netip.MustParseAddr("74.209.24.0"))
+	require.NoError(t, err)
+	assert.Equal(t, uint(14671), record.Traits.AutonomousSystemNumber)
+
+	require.NoError(t, reader.Close())
+}
+
+func TestOpenWatchedCloseFromCallback(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "GeoIP2-City.mmdb")
+	replaceFile(t, "test-data/test-data/GeoIP2-City-Test.mmdb", file)
+
+	readers := make(chan *ReloadableReader, 1)
+	closed := make(chan error, 1)
+	reader, err := OpenWatched(
+		file,
+		PollInterval(10*time.Millisecond),
+		OnReload(func(_, _ maxminddb.Metadata) {
+			closed <- (<-readers).Close()
+		}),
+	)
+	require.NoError(t, err)
+	readers <- reader
+
+	replaceFile(t, "test-data/test-data/GeoIP2-Enterprise-Test.mmdb", file)
+
+	select {
+	case err := <-closed:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for Close")
+	}
+	_, err = reader.City(netip.MustParseAddr("81.2.69.160"))
+	require.Error(t, err)
+}
+
+func TestOpenWatchedMissingFile(t *testing.T) {
+	_, err := OpenWatched(filepath.Join(t.TempDir(), "missing.mmdb"))
+	require.Error(t, err)
+}
+
+func TestOpenWatchedInvalidPollInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		_, err := OpenWatched(
+			"test-data/test-data/GeoIP2-City-Test.mmdb",
+			PollInterval(interval),
+		)
+		require.ErrorContains(t, err, "poll interval must be positive")
+	}
+}
+
+// replaceFile copies src to a temporary file and renames it over dst, the
+// same way geoipupdate replaces databases.
+func replaceFile(t *testing.T, src, dst string) {
+	t.Helper()
+
+	data, err := os.ReadFile(src)
+	require.NoError(t, err)
+
+	tmp := dst + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, data, 0o600))
+	require.NoError(t, os.Rename(tmp, dst))
+}